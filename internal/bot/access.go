@@ -0,0 +1,165 @@
+package bot
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const defaultInviteTTL = 24 * time.Hour
+
+// Invite одноразовый код приглашения для закрытого режима
+type Invite struct {
+	CreatedBy int64     `json:"created_by"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+var (
+	approvedChats     = make(map[int64]struct{})
+	approvedChatsLock sync.Mutex
+
+	invites     = make(map[string]Invite)
+	invitesLock sync.Mutex
+)
+
+// isPrivateMode возвращает true, если включён закрытый режим (PRIVATE_MODE в .env)
+func isPrivateMode() bool {
+	enabled, _ := strconv.ParseBool(os.Getenv("PRIVATE_MODE"))
+	return enabled
+}
+
+// isApproved проверяет, одобрен ли чат для работы с ботом
+func isApproved(chatID int64) bool {
+	approvedChatsLock.Lock()
+	defer approvedChatsLock.Unlock()
+	_, ok := approvedChats[chatID]
+	return ok
+}
+
+// approveChat добавляет чат в список одобренных и сохраняет настройки
+func approveChat(chatID int64) {
+	approvedChatsLock.Lock()
+	approvedChats[chatID] = struct{}{}
+	approvedChatsLock.Unlock()
+	saveSettings()
+}
+
+// hasAccess проверяет, может ли отправитель сообщения пользоваться ботом
+func hasAccess(msg *tgbotapi.Message) bool {
+	if !isPrivateMode() {
+		return true
+	}
+	return isAdmin(msg) || isApproved(msg.Chat.ID)
+}
+
+// chatHasAccess проверяет доступ чата для рассылок, где нет входящего сообщения
+func chatHasAccess(chatID int64) bool {
+	if !isPrivateMode() {
+		return true
+	}
+	if _, ok := getAdminIDs()[chatID]; ok {
+		return true
+	}
+	return isApproved(chatID)
+}
+
+// createInvite создаёт одноразовый код приглашения с ограниченным сроком действия
+func createInvite(createdBy int64, ttl time.Duration) (string, Invite, error) {
+	buf := make([]byte, 6)
+	if _, err := rand.Read(buf); err != nil {
+		return "", Invite{}, fmt.Errorf("не удалось сгенерировать код: %v", err)
+	}
+	code := strings.ToUpper(hex.EncodeToString(buf))
+	invite := Invite{
+		CreatedBy: createdBy,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+
+	invitesLock.Lock()
+	now := time.Now()
+	for c, inv := range invites {
+		if now.After(inv.ExpiresAt) {
+			delete(invites, c)
+		}
+	}
+	invites[code] = invite
+	invitesLock.Unlock()
+	saveSettings()
+
+	return code, invite, nil
+}
+
+// redeemInvite погашает код приглашения; код можно использовать только один раз
+func redeemInvite(code string) bool {
+	code = strings.ToUpper(strings.TrimSpace(code))
+
+	invitesLock.Lock()
+	invite, ok := invites[code]
+	if ok {
+		delete(invites, code)
+	}
+	invitesLock.Unlock()
+
+	if !ok {
+		return false
+	}
+	saveSettings()
+	return time.Now().Before(invite.ExpiresAt)
+}
+
+// handleInvite создаёт код приглашения: /invite [срок действия, например 12h]
+func (b *Bot) handleInvite(r *request) {
+	msg := r.Msg
+	lang := msgLang(msg)
+	ttl := defaultInviteTTL
+	if args := r.Arg(0); args != "" {
+		parsed, err := time.ParseDuration(args)
+		if err != nil || parsed <= 0 {
+			b.reply(msg, tr(lang, "invite.error"))
+			return
+		}
+		ttl = parsed
+	}
+
+	code, invite, err := createInvite(msg.Chat.ID, ttl)
+	if err != nil {
+		log.Printf("Ошибка создания приглашения: %v", err)
+		b.reply(msg, tr(lang, "invite.failed"))
+		return
+	}
+
+	expires := invite.ExpiresAt.In(chatLocation(msg.Chat.ID)).Format("02.01.2006 15:04 MST")
+	text := tr(lang, "invite.created", code, expires, b.bot.Self.UserName, code)
+	b.reply(msg, text)
+}
+
+// handleUnapproved обрабатывает команды из чатов без доступа в закрытом режиме
+func (b *Bot) handleUnapproved(r *request) {
+	msg := r.Msg
+	lang := msgLang(msg)
+	code := r.Arg(0)
+	if (r.Name == "redeem" || r.Name == "start") && code != "" {
+		if !redeemInvite(code) {
+			log.Printf("Неверный или просроченный код приглашения из чата %d", msg.Chat.ID)
+			b.reply(msg, tr(lang, "access.bad_code"))
+			return
+		}
+
+		log.Printf("Чат %d получил доступ по коду приглашения", msg.Chat.ID)
+		approveChat(msg.Chat.ID)
+		b.reply(msg, tr(lang, "access.granted"))
+		b.handleStart(r)
+		return
+	}
+
+	log.Printf("Отказ в доступе чату %d (команда /%s)", msg.Chat.ID, msg.Command())
+	b.reply(msg, tr(lang, "access.denied"))
+}
@@ -0,0 +1,290 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/joho/godotenv"
+)
+
+// exchangeStatus хранит результат последнего обновления ставок биржи
+type exchangeStatus struct {
+	LastUpdate  time.Time
+	LastAttempt time.Time
+	LastError   string
+	RatesCount  int
+}
+
+// Состояния биржи в отчёте о здоровье
+const (
+	healthOK      = "ok"
+	healthStale   = "stale"
+	healthError   = "error"
+	healthPending = "pending"
+)
+
+// exchangeHealth состояние биржи для панелей в терминале и браузере
+type exchangeHealth struct {
+	Exchange    string     `json:"exchange"`
+	State       string     `json:"state"`
+	Rates       int        `json:"rates"`
+	LastUpdate  *time.Time `json:"last_update,omitempty"`
+	LastAttempt *time.Time `json:"last_attempt,omitempty"`
+	Error       string     `json:"error,omitempty"`
+}
+
+// healthReport состояние бирж в порядке b.exchanges. Данные считаются устаревшими,
+// если биржа не обновлялась дольше двух циклов опроса.
+func (b *Bot) healthReport(now time.Time) []exchangeHealth {
+	b.statusLock.Lock()
+	defer b.statusLock.Unlock()
+
+	report := make([]exchangeHealth, 0, len(b.exchanges))
+	for _, ex := range b.exchanges {
+		health := exchangeHealth{Exchange: ex.GetName(), State: healthPending}
+		if status, ok := b.exchangeStatus[health.Exchange]; ok {
+			health.Rates = status.RatesCount
+			health.Error = status.LastError
+			lastAttempt := status.LastAttempt
+			health.LastAttempt = &lastAttempt
+			if !status.LastUpdate.IsZero() {
+				lastUpdate := status.LastUpdate
+				health.LastUpdate = &lastUpdate
+			}
+			switch {
+			case status.LastError != "":
+				health.State = healthError
+			case now.Sub(status.LastUpdate) > 2*ratesUpdateInterval:
+				health.State = healthStale
+			default:
+				health.State = healthOK
+			}
+		}
+		report = append(report, health)
+	}
+	return report
+}
+
+// getAdminIDs возвращает список chat ID администраторов из ADMIN_CHAT_IDS
+func getAdminIDs() map[int64]struct{} {
+	admins := make(map[int64]struct{})
+	for _, part := range strings.Split(os.Getenv("ADMIN_CHAT_IDS"), ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(part, 10, 64)
+		if err != nil {
+			log.Printf("Некорректный ID администратора %q: %v", part, err)
+			continue
+		}
+		admins[id] = struct{}{}
+	}
+	return admins
+}
+
+// isAdmin проверяет, входит ли чат или отправитель сообщения в список администраторов
+func isAdmin(msg *tgbotapi.Message) bool {
+	admins := getAdminIDs()
+	if _, ok := admins[msg.Chat.ID]; ok {
+		return true
+	}
+	if msg.From != nil {
+		if _, ok := admins[msg.From.ID]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// recordExchangeStatus запоминает результат обновления ставок биржи
+func (b *Bot) recordExchangeStatus(exchangeName string, ratesCount int, err error) {
+	b.statusLock.Lock()
+	defer b.statusLock.Unlock()
+
+	status, ok := b.exchangeStatus[exchangeName]
+	if !ok {
+		status = &exchangeStatus{}
+		b.exchangeStatus[exchangeName] = status
+	}
+
+	status.LastAttempt = time.Now()
+	if err != nil {
+		status.LastError = err.Error()
+		return
+	}
+	status.LastUpdate = status.LastAttempt
+	status.LastError = ""
+	status.RatesCount = ratesCount
+}
+
+// handlePause приостанавливает плановую рассылку
+func (b *Bot) handlePause(r *request) {
+	msg := r.Msg
+	b.broadcastPaused.Store(true)
+	b.reply(msg, tr(msgLang(msg), "admin.paused"))
+}
+
+// handleResume возобновляет плановую рассылку
+func (b *Bot) handleResume(r *request) {
+	msg := r.Msg
+	b.broadcastPaused.Store(false)
+	b.reply(msg, tr(msgLang(msg), "admin.resumed"))
+}
+
+// handleStatus показывает состояние бирж, очереди RabbitMQ и рассылки
+func (b *Bot) handleStatus(r *request) {
+	msg := r.Msg
+	lang := msgLang(msg)
+	loc := chatLocation(msg.Chat.ID)
+	b.statusLock.Lock()
+	names := make([]string, 0, len(b.exchanges))
+	for _, ex := range b.exchanges {
+		names = append(names, ex.GetName())
+	}
+	var lines []string
+	for _, name := range names {
+		status, ok := b.exchangeStatus[name]
+		if !ok {
+			lines = append(lines, tr(lang, "status.not_updated", name))
+			continue
+		}
+		updated := tr(lang, "status.never")
+		if !status.LastUpdate.IsZero() {
+			updated = formatAgo(status.LastUpdate, loc, lang)
+		}
+		line := trn(lang, "status.line", status.RatesCount, name, status.RatesCount, updated)
+		if status.LastError != "" {
+			line += tr(lang, "status.error", status.LastError)
+		}
+		lines = append(lines, line)
+	}
+	lastBroadcast := b.lastBroadcast
+	b.statusLock.Unlock()
+
+	subscribersLock.Lock()
+	subscribersCount := len(subscribers)
+	subscribersLock.Unlock()
+
+	broadcastState := tr(lang, "status.active")
+	if b.broadcastPaused.Load() {
+		broadcastState = tr(lang, "status.paused")
+	}
+	lastBroadcastText := tr(lang, "status.no_broadcast")
+	if !lastBroadcast.IsZero() {
+		lastBroadcastText = formatAgo(lastBroadcast, loc, lang)
+	}
+
+	text := tr(lang, "status.title") +
+		"<pre>" + escapeHTML(strings.Join(lines, "\n")) + "</pre>\n" +
+		tr(lang, "status.rabbit", len(b.fundingChan), cap(b.fundingChan)) +
+		tr(lang, "status.retry", b.retryPending.Load()) +
+		formatDispatcherStats(b.dispatcher.Stats(), lang) +
+		tr(lang, "status.subscribers", subscribersCount) +
+		tr(lang, "status.broadcast", broadcastState, lastBroadcastText)
+
+	b.reply(msg, text)
+}
+
+// handleBroadcast рассылает произвольный текст всем подписчикам
+func (b *Bot) handleBroadcast(r *request) {
+	msg := r.Msg
+	text := r.Text
+	if text == "" {
+		b.reply(msg, tr(msgLang(msg), "broadcast.usage"))
+		return
+	}
+
+	recipients := b.recipients()
+	var wg sync.WaitGroup
+	for _, to := range recipients {
+		wg.Add(1)
+		go func(to recipient) {
+			defer wg.Done()
+			if err := to.Notifier.NotifyText(to, text); err != nil {
+				log.Printf("Ошибка отправки объявления получателю %s: %v", to.Key, err)
+			}
+		}(to)
+	}
+	wg.Wait()
+
+	b.reply(msg, trn(msgLang(msg), "broadcast.sent", len(recipients)))
+}
+
+// handleUsers показывает количество подписчиков и их пороги
+func (b *Bot) handleUsers(r *request) {
+	msg := r.Msg
+	subscribersLock.Lock()
+	ids := make([]int64, 0, len(subscribers))
+	for id := range subscribers {
+		ids = append(ids, id)
+	}
+	subscribersLock.Unlock()
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	lang := msgLang(msg)
+	lines := make([]string, 0, len(ids))
+	for _, id := range ids {
+		_, source := getUserThreshold(id)
+		lines = append(lines, fmt.Sprintf("%-16d %s (%s)", id, getRateThresholds(id).describe(lang),
+			tr(lang, "threshold.source."+source)))
+	}
+
+	text := tr(lang, "users.title", len(ids), getDefaultThreshold()*100)
+	if len(lines) > 0 {
+		text += "\n<pre>" + strings.Join(lines, "\n") + "</pre>"
+	}
+	b.reply(msg, text)
+}
+
+// handleReload перечитывает .env и файл настроек
+func (b *Bot) handleReload(r *request) {
+	msg := r.Msg
+	if err := godotenv.Overload(); err != nil {
+		log.Printf("Не удалось перечитать .env: %v", err)
+	}
+	loadSettings()
+	b.loadTargets()
+
+	subscribersLock.Lock()
+	subscribersCount := len(subscribers)
+	subscribersLock.Unlock()
+
+	b.reply(msg, tr(msgLang(msg), "reload.done", subscribersCount, b.targetsCount(), len(getAdminIDs())))
+}
+
+// handleForceUpdate немедленно обновляет ставки всех бирж
+func (b *Bot) handleForceUpdate(r *request) {
+	msg := r.Msg
+	lang := msgLang(msg)
+	b.reply(msg, tr(lang, "force_update.started"))
+	started := time.Now()
+	b.updateAllRates()
+	b.reply(msg, tr(lang, "force_update.done", time.Since(started).Truncate(time.Millisecond)))
+}
+
+// formatAgo возвращает время события в часовом поясе чата и сколько прошло с тех пор
+func formatAgo(t time.Time, loc *time.Location, lang string) string {
+	return tr(lang, "ago", t.In(loc).Format("15:04:05"), time.Since(t).Truncate(time.Second))
+}
+
+// escapeHTML экранирует спецсимволы для ParseMode HTML
+func escapeHTML(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+// formatDispatcherStats форматирует метрики очереди исходящих сообщений
+func formatDispatcherStats(stats dispatcherStats, lang string) string {
+	return tr(lang, "status.dispatcher_queue",
+		stats.Queued[priorityReply], stats.Queued[priorityBroadcast], stats.InFlight) +
+		tr(lang, "status.dispatcher_sent",
+			stats.Sent, stats.Failed, stats.RateLimited,
+			stats.AvgWait.Truncate(time.Millisecond), stats.MaxWait.Truncate(time.Millisecond))
+}
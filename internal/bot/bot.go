@@ -3,12 +3,10 @@ package bot
 import (
 	"fmt"
 	"log"
-	"math"
 	"os"
-	"sort"
-	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"encoding/json"
@@ -24,6 +22,43 @@ type Bot struct {
 	exchanges   []exchanges.Exchange
 	cache       *exchanges.RatesCache
 	fundingChan chan<- *proto.FundingRate
+
+	// updateLock не даёт плановому и принудительному обновлению идти одновременно
+	updateLock sync.Mutex
+
+	statusLock     sync.Mutex
+	exchangeStatus map[string]*exchangeStatus
+	lastBroadcast  time.Time
+
+	broadcastPaused atomic.Bool
+
+	// messageThreads тема форума для входящих сообщений, пока они обрабатываются
+	messageThreads sync.Map
+
+	// retryPending количество сообщений, ожидающих повторной отправки
+	retryPending atomic.Int64
+
+	// dispatcher через него проходят все исходящие сообщения
+	dispatcher *dispatcher
+
+	// lastSent время последней плановой рассылки каждому получателю (по recipient.Key)
+	scheduleLock sync.Mutex
+	lastSent     map[string]time.Time
+
+	// notifiers каналы доставки рассылок; targets — получатели во внешних каналах из NOTIFY_TARGETS
+	notifiers   map[string]Notifier
+	targetsLock sync.Mutex
+	targets     []recipient
+
+	// router цепочка middleware, через которую проходят все команды
+	router  handlerFunc
+	limiter *rateLimiter
+
+	// history локальная история ставок для графиков
+	history *rateHistory
+
+	// updates оповещает веб-панель о завершении обновления ставок
+	updates updateFeed
 }
 
 var (
@@ -40,6 +75,10 @@ var (
 type Settings struct {
 	Subscribers []int64           `json:"subscribers"`
 	Thresholds  map[int64]float64 `json:"thresholds"`
+	Approved    []int64           `json:"approved,omitempty"`
+	Invites     map[string]Invite `json:"invites,omitempty"`
+
+	Chats map[int64]*ChatSettings `json:"chats,omitempty"`
 }
 
 // loadSettings загружает все настройки из файла
@@ -54,6 +93,7 @@ func loadSettings() {
 	var settings Settings
 	if err := json.NewDecoder(file).Decode(&settings); err == nil {
 		subscribersLock.Lock()
+		subscribers = make(map[int64]struct{}, len(settings.Subscribers))
 		for _, id := range settings.Subscribers {
 			subscribers[id] = struct{}{}
 		}
@@ -62,8 +102,34 @@ func loadSettings() {
 
 		userThresholdsLock.Lock()
 		userThresholds = settings.Thresholds
+		if userThresholds == nil {
+			userThresholds = make(map[int64]float64)
+		}
 		userThresholdsLock.Unlock()
 		log.Printf("Загружено порогов: %d", len(settings.Thresholds))
+
+		approvedChatsLock.Lock()
+		approvedChats = make(map[int64]struct{}, len(settings.Approved))
+		for _, id := range settings.Approved {
+			approvedChats[id] = struct{}{}
+		}
+		approvedChatsLock.Unlock()
+
+		invitesLock.Lock()
+		invites = settings.Invites
+		if invites == nil {
+			invites = make(map[string]Invite)
+		}
+		invitesLock.Unlock()
+		log.Printf("Загружено одобренных чатов: %d, приглашений: %d", len(settings.Approved), len(settings.Invites))
+
+		chatSettingsLock.Lock()
+		chatSettings = settings.Chats
+		if chatSettings == nil {
+			chatSettings = make(map[int64]*ChatSettings)
+		}
+		chatSettingsLock.Unlock()
+		log.Printf("Загружено настроек чатов: %d", len(settings.Chats))
 	} else {
 		log.Printf("Ошибка декодирования файла настроек: %v", err)
 	}
@@ -73,29 +139,44 @@ func loadSettings() {
 func saveSettings() {
 	subscribersLock.Lock()
 	userThresholdsLock.Lock()
+	approvedChatsLock.Lock()
+	invitesLock.Lock()
+	chatSettingsLock.Lock()
 
 	subscribers_list := make([]int64, 0, len(subscribers))
 	for id := range subscribers {
 		subscribers_list = append(subscribers_list, id)
 	}
 
+	approved_list := make([]int64, 0, len(approvedChats))
+	for id := range approvedChats {
+		approved_list = append(approved_list, id)
+	}
+
 	settings := Settings{
 		Subscribers: subscribers_list,
 		Thresholds:  userThresholds,
+		Approved:    approved_list,
+		Invites:     invites,
+		Chats:       chatSettings,
 	}
 
+	// Кодируем под блокировками, чтобы карты не менялись во время записи
+	data, err := json.Marshal(settings)
+
 	subscribersLock.Unlock()
 	userThresholdsLock.Unlock()
+	approvedChatsLock.Unlock()
+	invitesLock.Unlock()
+	chatSettingsLock.Unlock()
 
-	file, err := os.Create(settingsFile)
 	if err != nil {
-		log.Printf("Ошибка создания файла настроек: %v", err)
+		log.Printf("Ошибка сохранения настроек: %v", err)
 		return
 	}
-	defer file.Close()
 
-	if err := json.NewEncoder(file).Encode(settings); err != nil {
-		log.Printf("Ошибка сохранения настроек: %v", err)
+	if err := os.WriteFile(settingsFile, append(data, '\n'), 0644); err != nil {
+		log.Printf("Ошибка создания файла настроек: %v", err)
 	}
 }
 
@@ -118,17 +199,14 @@ func setUserThreshold(userID int64, threshold float64) {
 	saveSettings()
 }
 
-// Получить глобальный порог из .env
+// Получить глобальный порог из .env; значение в процентах, как у /threshold
 func getDefaultThreshold() float64 {
 	val := os.Getenv("DEFAULT_FUNDING_THRESHOLD")
 	if val == "" {
 		return 0.001 // 0.1% по умолчанию
 	}
 
-	// Убираем знак процента, если он есть
-	val = strings.TrimSuffix(val, "%")
-
-	f, err := strconv.ParseFloat(val, 64)
+	f, err := parseThresholdValue(val)
 	if err != nil {
 		return 0.001
 	}
@@ -143,17 +221,46 @@ func NewBot(token string, exs []exchanges.Exchange, fundingChan chan<- *proto.Fu
 	}
 
 	log.Printf("Бот создан: @%s", bot.Self.UserName)
+	b := &Bot{
+		bot:            bot,
+		exchanges:      exs,
+		cache:          exchanges.GetGlobalCache(),
+		fundingChan:    fundingChan,
+		exchangeStatus: make(map[string]*exchangeStatus),
+		lastSent:       make(map[string]time.Time),
+		notifiers:      newExternalNotifiers(),
+		limiter:        newRateLimiter(commandBurst, commandRefill),
+		history:        newRateHistory(),
+	}
+	b.notifiers[channelTelegram] = telegramNotifier{b}
+	b.dispatcher = newDispatcher(b.sendMessage)
+	b.router = b.newRouter()
+	return b
+}
+
+// newHeadlessBot создаёт бота без Telegram и RabbitMQ: он только опрашивает биржи в кэш.
+// Используется панелями в терминале и браузере; с history == nil история ставок не пишется.
+func newHeadlessBot(exs []exchanges.Exchange, history *rateHistory) *Bot {
 	return &Bot{
-		bot:         bot,
-		exchanges:   exs,
-		cache:       exchanges.GetGlobalCache(),
-		fundingChan: fundingChan,
+		exchanges:      exs,
+		cache:          exchanges.GetGlobalCache(),
+		exchangeStatus: make(map[string]*exchangeStatus),
+		history:        history,
 	}
 }
 
 func (b *Bot) Start() error {
 	log.Println("Запуск бота...")
 	loadSettings()
+	b.loadLastSent()
+	b.loadTargets()
+	b.registerCommands()
+
+	// Запускаем очередь исходящих сообщений
+	go b.dispatcher.run()
+
+	// Живые табло подписываются на обновления до первого опроса бирж
+	go b.startLiveBoardLoop(b.updates.subscribe())
 
 	// Запускаем горутину для обновления кэша ставок
 	go b.startRatesUpdateLoop()
@@ -161,23 +268,36 @@ func (b *Bot) Start() error {
 	// Запускаем горутину для рассылки уведомлений
 	go b.startBroadcastLoop()
 
-	u := tgbotapi.NewUpdate(0)
-	u.Timeout = 60
-
-	updates := b.bot.GetUpdatesChan(u)
-	for update := range updates {
-		if update.Message == nil {
-			continue
+	// Веб-панель включается адресом в WEB_ADDR
+	if addr := os.Getenv("WEB_ADDR"); addr != "" {
+		go func() {
+			if err := b.serveWeb(addr); err != nil {
+				log.Printf("Ошибка веб-панели: %v", err)
+			}
+		}()
+	}
+
+	for update := range b.pollUpdates() {
+		switch {
+		case update.Message != nil:
+			go b.handleMessage(update.Message, update.ThreadID)
+		case update.ChannelPost != nil:
+			go b.handleMessage(update.ChannelPost, 0)
+		case update.MyChatMember != nil:
+			go b.handleMyChatMember(update.MyChatMember)
+		case update.CallbackQuery != nil:
+			go b.handleCallback(update.CallbackQuery)
 		}
-
-		go b.handleMessage(update.Message)
 	}
 
 	return nil
 }
 
+// ratesUpdateInterval период опроса бирж
+const ratesUpdateInterval = 2 * time.Minute
+
 func (b *Bot) startRatesUpdateLoop() {
-	ticker := time.NewTicker(2 * time.Minute)
+	ticker := time.NewTicker(ratesUpdateInterval)
 	defer ticker.Stop()
 
 	for {
@@ -187,6 +307,9 @@ func (b *Bot) startRatesUpdateLoop() {
 }
 
 func (b *Bot) updateAllRates() {
+	b.updateLock.Lock()
+	defer b.updateLock.Unlock()
+
 	var wg sync.WaitGroup
 	for _, ex := range b.exchanges {
 		wg.Add(1)
@@ -200,40 +323,28 @@ func (b *Bot) updateAllRates() {
 			if err := b.cache.UpdateRates(exchange); err != nil {
 				log.Printf("Ошибка обновления ставок для %s: %v", exchange.GetName(), err)
 				exchangeLogger.Printf("Ошибка обновления ставок: %v", err)
+				b.recordExchangeStatus(exchange.GetName(), 0, err)
 				return
 			}
 
 			// Получаем актуальные ставки после обновления
 			rates := b.cache.GetRates(exchange.GetName())
+			b.recordExchangeStatus(exchange.GetName(), len(rates), nil)
 			exchangeLogger.Printf("Обработка %d ставок завершена", len(rates))
 
 			for _, rate := range rates {
-				// Преобразуем время следующего фандинга
-				var timestamp int64
-				if rate.NextFunding != "Неизвестно" {
-					if t, err := time.Parse(time.RFC3339, rate.NextFunding); err == nil {
-						tz := os.Getenv("TIMEZONE")
-						if tz != "" {
-							if loc, err := time.LoadLocation(tz); err == nil {
-								t = t.In(loc)
-							}
-						}
-						timestamp = t.Unix()
-					} else {
-						exchangeLogger.Printf("Ошибка парсинга времени фандинга для %s: %v", rate.Symbol, err)
-					}
+				message := fundingMessage(exchange.GetName(), rate)
+				if message.Timestamp == 0 && rate.NextFunding != "Неизвестно" {
+					exchangeLogger.Printf("Ошибка парсинга времени фандинга для %s: %q", rate.Symbol, rate.NextFunding)
+				}
+				if b.fundingChan == nil {
+					// Панель в терминале обновляет только кэш, без публикации в RabbitMQ
+					continue
 				}
 
 				// Отправляем в RabbitMQ канал
 				select {
-				case b.fundingChan <- &proto.FundingRate{
-					Exchange:       exchange.GetName(),
-					Symbol:         rate.Symbol,
-					Rate:           rate.Rate,
-					Timestamp:      timestamp,
-					Volume_24H:     rate.Volume24h,
-					VolumeUsdt_24H: rate.VolumeUSDT24h,
-				}:
+				case b.fundingChan <- message:
 				default:
 					log.Printf("Канал заполнен, пропускаю ставку %s от %s", rate.Symbol, exchange.GetName())
 					exchangeLogger.Printf("Канал заполнен, пропускаю ставку %s", rate.Symbol)
@@ -242,96 +353,146 @@ func (b *Bot) updateAllRates() {
 		}(ex)
 	}
 	wg.Wait()
+
+	if b.history != nil {
+		b.history.record(b.cache.GetAllRates(), time.Now())
+	}
+	b.updates.publish(time.Now())
 }
 
-func (b *Bot) handleRates(msg *tgbotapi.Message) {
+// fundingMessage преобразует ставку в сообщение для RabbitMQ
+func fundingMessage(exchangeName string, rate exchanges.FundingRate) *proto.FundingRate {
+	// Unix-время не зависит от часового пояса, перевод в TIMEZONE здесь не нужен
+	var timestamp int64
+	if t, ok := nextFundingTime(rate); ok {
+		timestamp = t.Unix()
+	}
+	return &proto.FundingRate{
+		Exchange:       exchangeName,
+		Symbol:         rate.Symbol,
+		Rate:           rate.Rate,
+		Timestamp:      timestamp,
+		Volume_24H:     rate.Volume24h,
+		VolumeUsdt_24H: rate.VolumeUSDT24h,
+	}
+}
+
+func (b *Bot) handleRates(r *request) {
+	msg := r.Msg
 	rates := b.cache.GetAllRates()
 	if len(rates) == 0 {
-		b.sendLongMessage(msg.Chat.ID, "Нет доступных ставок фандинга")
+		b.reply(msg, tr(msgLang(msg), "rates.empty"))
 		return
 	}
 
-	threshold, _ := getUserThreshold(msg.Chat.ID)
-	formattedRates := formatRates(rates, threshold)
-	b.sendLongMessage(msg.Chat.ID, formattedRates)
+	view := ratesView{Exchange: b.firstExchangeWithRates(msg.Chat.ID), Sort: sortByAbsRate}
+	text, markup := b.renderRatesView(msg.Chat.ID, view, msgLang(msg))
+	_, err := b.deliver([]*outgoingMessage{{
+		Target:   chatTarget{ChatID: msg.Chat.ID, ThreadID: b.threadOf(msg)},
+		Text:     text,
+		Markup:   markup,
+		Priority: priorityReply,
+	}})
+	if err != nil {
+		log.Printf("Ошибка отправки /rates в чат %d: %v", msg.Chat.ID, err)
+	}
 }
 
-func (b *Bot) handleMessage(msg *tgbotapi.Message) {
-	if msg.Command() == "start" {
-		go b.handleStart(msg)
-	} else if msg.Command() == "rates" {
-		go b.handleRates(msg)
-	} else if msg.Command() == "subscribe" {
-		go b.handleSubscribe(msg)
-	} else if msg.Command() == "unsubscribe" {
-		go b.handleUnsubscribe(msg)
-	} else if msg.Command() == "threshold" {
-		go b.handleThreshold(msg)
+func (b *Bot) handleMessage(msg *tgbotapi.Message, threadID int) {
+	defer logPanic("обработке сообщения")
+
+	if msg.MigrateToChatID != 0 {
+		migrateChat(msg.Chat.ID, msg.MigrateToChatID)
+		return
+	}
+	if !msg.IsCommand() {
+		return
 	}
+	// В группах команда может быть адресована другому боту: /rates@other_bot
+	if at := strings.Index(msg.CommandWithAt(), "@"); at != -1 &&
+		!strings.EqualFold(msg.CommandWithAt()[at+1:], b.bot.Self.UserName) {
+		return
+	}
+
+	r := &request{Msg: msg, ThreadID: threadID, Name: strings.ToLower(msg.Command())}
+	if cmd, ok := lookupCommand(r.Name); ok {
+		r.Command = &cmd
+	}
+	b.router(r)
 }
 
-func (b *Bot) handleSubscribe(msg *tgbotapi.Message) {
-	log.Printf("Обработка команды subscribe от пользователя %s", msg.From.UserName)
+func (b *Bot) handleSubscribe(r *request) {
+	msg := r.Msg
+	log.Printf("Обработка команды subscribe от пользователя %s", senderName(msg))
+	if !b.canChangeSettings(msg) {
+		b.reply(msg, tr(msgLang(msg), "subscribe.admin_only"))
+		return
+	}
 	subscribersLock.Lock()
 	subscribers[msg.Chat.ID] = struct{}{}
 	subscribersLock.Unlock()
+	// Рассылка пойдёт в ту тему форума, где была оформлена подписка
+	threadID := b.threadOf(msg)
+	updateChatSettings(msg.Chat.ID, func(settings *ChatSettings) {
+		settings.ThreadID = threadID
+	})
 	// Устанавливаем порог по умолчанию для нового пользователя
 	setUserThreshold(msg.Chat.ID, getDefaultThreshold())
 	saveSettings()
-	b.sendLongMessage(msg.Chat.ID, "Вы успешно подписались на уведомления!")
+	b.reply(msg, tr(msgLang(msg), "subscribe.done"))
 	// Сразу отправляем ставки после подписки
 	go b.sendCurrentRatesToUser(msg.Chat.ID)
 }
 
 // sendCurrentRatesToUser отправляет актуальные ставки фандинга пользователю
 func (b *Bot) sendCurrentRatesToUser(chatID int64) {
+	lang := chatLang(chatID)
 	rates := b.cache.GetAllRates()
 	if len(rates) == 0 {
-		b.sendLongMessage(chatID, "Нет доступных ставок фандинга")
+		b.sendLongMessageTo(targetFor(chatID), tr(lang, "rates.empty"), priorityReply)
 		return
 	}
 
-	threshold, _ := getUserThreshold(chatID)
-	formattedRates := formatRates(rates, threshold)
-	b.sendLongMessage(chatID, formattedRates)
+	opts := b.telegramRecipient(chatID).reportOptions(time.Now())
+	opts.Digest = false
+	opts.RowLimit = telegramRowLimit
+	report := buildRatesReport(rates, buildPriceIndex(rates), opts)
+	b.sendLongMessageTo(targetFor(chatID), renderTelegramHTML(report), priorityReply)
 }
 
-func (b *Bot) handleUnsubscribe(msg *tgbotapi.Message) {
-	log.Printf("Обработка команды unsubscribe от пользователя %s", msg.From.UserName)
+func (b *Bot) handleUnsubscribe(r *request) {
+	msg := r.Msg
+	log.Printf("Обработка команды unsubscribe от пользователя %s", senderName(msg))
+	if !b.canChangeSettings(msg) {
+		b.reply(msg, tr(msgLang(msg), "subscribe.admin_only"))
+		return
+	}
 	subscribersLock.Lock()
 	delete(subscribers, msg.Chat.ID)
 	subscribersLock.Unlock()
 	saveSettings()
-	b.sendLongMessage(msg.Chat.ID, "Вы успешно отписались от уведомлений.")
+	b.reply(msg, tr(msgLang(msg), "unsubscribe.done"))
 }
 
+// startBroadcastLoop раз в минуту рассылает ставки чатам, которым пора по их расписанию
 func (b *Bot) startBroadcastLoop() {
-	const interval = 5 * time.Minute
-	b.broadcastFundingRates() // первая рассылка сразу
+	ticker := time.NewTicker(scheduleTick)
+	defer ticker.Stop()
+
 	for {
-		next := time.Now().Add(interval)
-		for {
-			now := time.Now()
-			if now.After(next) {
-				break
-			}
-			left := next.Sub(now)
-			log.Printf("До следующей рассылки осталось: %v", left.Truncate(time.Second))
-			time.Sleep(1 * time.Minute)
-		}
-		b.broadcastFundingRates()
+		b.broadcastFundingRates() // первая рассылка сразу
+		<-ticker.C
 	}
 }
 
 func (b *Bot) broadcastFundingRates() {
-	subscribersLock.Lock()
-	ids := make([]int64, 0, len(subscribers))
-	for id := range subscribers {
-		ids = append(ids, id)
+	if b.broadcastPaused.Load() {
+		log.Println("Рассылка приостановлена администратором, пропускаю")
+		return
 	}
-	subscribersLock.Unlock()
 
-	if len(ids) == 0 {
+	recipients := b.recipients()
+	if len(recipients) == 0 {
 		return
 	}
 
@@ -340,268 +501,240 @@ func (b *Bot) broadcastFundingRates() {
 		return
 	}
 
-	for _, chatID := range ids {
-		threshold, _ := getUserThreshold(chatID)
-		formattedRates := formatRates(rates, threshold)
-		if formattedRates != "" {
-			b.sendLongMessage(chatID, formattedRates)
-		}
+	now := time.Now()
+	due := b.dueRecipients(recipients, now)
+	if len(due) == 0 {
+		return
 	}
+
+	// Сообщения всем получателям отправляются сразу; в Telegram темп задаёт диспетчер
+	prices := buildPriceIndex(rates)
+	var wg sync.WaitGroup
+	for _, to := range due {
+		b.markBroadcastSent(to.Key, now)
+		wg.Add(1)
+		go func(to recipient, alert *ratesAlert) {
+			defer wg.Done()
+			if err := to.Notifier.NotifyRates(to, alert); err != nil {
+				log.Printf("Ошибка рассылки получателю %s: %v", to.Key, err)
+			}
+		}(to, &ratesAlert{Rates: rates, Prices: prices, Options: to.reportOptions(now)})
+	}
+	b.pruneLastSent(recipients)
+	b.saveLastSent()
+	wg.Wait()
+
+	b.statusLock.Lock()
+	b.lastBroadcast = now
+	b.statusLock.Unlock()
 }
 
-// sendLongMessage отправляет длинное сообщение, разбивая его на части, если оно слишком большое
+// sendLongMessage отправляет рассылку в чат (в тему форума, выбранную при подписке)
 func (b *Bot) sendLongMessage(chatID int64, text string) {
-	const maxLength = 4000
-
-	// Если есть <pre>...</pre> блоки, разбиваем только по ним
-	if strings.Contains(text, "<pre>") {
-		blocks := splitByPreBlocks(text)
-		for i, block := range blocks {
-			if len(block) <= maxLength {
-				msg := tgbotapi.NewMessage(chatID, block)
-				msg.ParseMode = "HTML"
-				if _, err := b.bot.Send(msg); err != nil {
-					log.Printf("Ошибка отправки блока %d/%d: %v", i+1, len(blocks), err)
-				}
-			} else {
-				// Если блок всё равно слишком большой, отправляем как есть (Telegram сам обрежет)
-				msg := tgbotapi.NewMessage(chatID, block[:maxLength])
-				msg.ParseMode = "HTML"
-				if _, err := b.bot.Send(msg); err != nil {
-					log.Printf("Ошибка отправки длинного блока %d/%d: %v", i+1, len(blocks), err)
-				}
-			}
-		}
-		return
+	b.sendLongMessageTo(targetFor(chatID), text, priorityBroadcast)
+}
+
+// reply отвечает на команду в тот же чат и ту же тему форума; ответы идут вне очереди рассылок
+func (b *Bot) reply(msg *tgbotapi.Message, text string) {
+	b.sendLongMessageTo(chatTarget{ChatID: msg.Chat.ID, ThreadID: b.threadOf(msg)}, text, priorityReply)
+}
+
+// sendMessage отправляет или редактирует одно сообщение с HTML-разметкой в обход очереди —
+// вызывается только диспетчером. Запрос собирается вручную: tgbotapi не поддерживает message_thread_id.
+func (b *Bot) sendMessage(msg *outgoingMessage) (int, error) {
+	if msg.File != nil {
+		return b.sendFile(msg)
 	}
 
-	// Обычная логика для текстов без <pre>
-	if len(text) <= maxLength {
-		msg := tgbotapi.NewMessage(chatID, text)
-		msg.ParseMode = "HTML"
-		if _, err := b.bot.Send(msg); err != nil {
-			log.Printf("Ошибка отправки сообщения: %v", err)
-		}
-		return
+	params := tgbotapi.Params{}
+	params.AddNonZero64("chat_id", msg.Target.ChatID)
+	params.AddNonEmpty("text", msg.Text)
+	params.AddNonEmpty("parse_mode", tgbotapi.ModeHTML)
+	if err := params.AddInterface("reply_markup", msg.Markup); err != nil {
+		return 0, err
 	}
 
-	// Разбиваем по строкам
-	lines := strings.Split(text, "\n")
-	currentPart := ""
-	parts := []string{}
-	for _, line := range lines {
-		if len(currentPart)+len(line)+1 > maxLength {
-			if currentPart != "" {
-				parts = append(parts, currentPart)
-				currentPart = ""
-			}
-			if len(line) > maxLength {
-				for i := 0; i < len(line); i += maxLength {
-					end := i + maxLength
-					if end > len(line) {
-						end = len(line)
-					}
-					parts = append(parts, line[i:end])
-				}
-			} else {
-				currentPart = line
-			}
-		} else {
-			if currentPart != "" {
-				currentPart += "\n"
-			}
-			currentPart += line
-		}
+	method := "sendMessage"
+	if msg.EditMessageID != 0 {
+		method = "editMessageText"
+		params.AddNonZero("message_id", msg.EditMessageID)
+	} else {
+		params.AddNonZero("message_thread_id", msg.Target.ThreadID)
 	}
-	if currentPart != "" {
-		parts = append(parts, currentPart)
+
+	resp, err := b.bot.MakeRequest(method, params)
+	if err != nil {
+		return 0, err
 	}
-	for i, part := range parts {
-		msg := tgbotapi.NewMessage(chatID, part)
-		msg.ParseMode = "HTML"
-		if _, err := b.bot.Send(msg); err != nil {
-			log.Printf("Ошибка отправки части %d/%d: %v", i+1, len(parts), err)
-		}
+
+	var sent tgbotapi.Message
+	if err := json.Unmarshal(resp.Result, &sent); err != nil {
+		log.Printf("Не удалось разобрать ответ %s: %v", method, err)
 	}
+	return sent.MessageID, nil
 }
 
-// splitByPreBlocks разбивает текст на блоки по <pre>...</pre>
-func splitByPreBlocks(text string) []string {
-	var blocks []string
-	start := 0
-	for {
-		preStart := strings.Index(text[start:], "<pre>")
-		if preStart == -1 {
-			if start < len(text) {
-				blocks = append(blocks, text[start:])
-			}
-			break
-		}
-		preStart += start
-		preEnd := strings.Index(text[preStart:], "</pre>")
-		if preEnd == -1 {
-			// Нет закрывающего тега, добавляем остаток
-			blocks = append(blocks, text[preStart:])
-			break
-		}
-		preEnd += preStart + len("</pre>")
-		if preStart > start {
-			blocks = append(blocks, text[start:preStart])
-		}
-		blocks = append(blocks, text[preStart:preEnd])
-		start = preEnd
+// sendFile отправляет файл с подписью; как и sendMessage, собирает запрос вручную ради message_thread_id
+func (b *Bot) sendFile(msg *outgoingMessage) (int, error) {
+	params := tgbotapi.Params{}
+	params.AddNonZero64("chat_id", msg.Target.ChatID)
+	params.AddNonZero("message_thread_id", msg.Target.ThreadID)
+	params.AddNonEmpty("caption", msg.Text)
+	if msg.Text != "" {
+		params.AddNonEmpty("parse_mode", tgbotapi.ModeHTML)
+	}
+	if err := params.AddInterface("reply_markup", msg.Markup); err != nil {
+		return 0, err
+	}
+
+	resp, err := b.bot.UploadFiles(msg.File.Method, params, []tgbotapi.RequestFile{{
+		Name: msg.File.Field,
+		Data: msg.File.Data,
+	}})
+	if err != nil {
+		return 0, err
+	}
+
+	var sent tgbotapi.Message
+	if err := json.Unmarshal(resp.Result, &sent); err != nil {
+		log.Printf("Не удалось разобрать ответ %s: %v", msg.File.Method, err)
 	}
-	return blocks
+	return sent.MessageID, nil
 }
 
-func (b *Bot) handleStart(msg *tgbotapi.Message) {
-	log.Printf("Обработка команды start от пользователя %s", msg.From.UserName)
-	text := "Привет! Я бот для мониторинга ставок фандинга.\n" +
-		"Доступные команды:\n" +
-		"/rates - показать текущие ставки фандинга\n" +
-		"/subscribe - подписаться на уведомления\n" +
-		"/unsubscribe - отписаться от уведомлений\n" +
-		"/threshold - показать текущий порог\n" +
-		"/threshold X.XXX - установить новый порог (например: /threshold 0.1)"
-
-	b.sendLongMessage(msg.Chat.ID, text)
+// replyFile отвечает на команду файлом с подписью в тот же чат и ту же тему форума
+func (b *Bot) replyFile(msg *tgbotapi.Message, file *attachment, caption string) {
+	_, err := b.deliver([]*outgoingMessage{{
+		Target:   chatTarget{ChatID: msg.Chat.ID, ThreadID: b.threadOf(msg)},
+		Text:     caption,
+		File:     file,
+		Priority: priorityReply,
+	}})
+	if err != nil {
+		log.Printf("Ошибка отправки файла %s в чат %d: %v", file.Data.Name, msg.Chat.ID, err)
+	}
 }
 
-func formatRates(rates map[string][]exchanges.FundingRate, threshold float64) string {
-	log.Printf("Форматирование ставок с порогом %.6f", threshold)
-	var result []string
-
-	for exchangeName, exchangeRates := range rates {
-		log.Printf("Обработка %d ставок с биржи %s", len(exchangeRates), exchangeName)
-
-		// Сортируем ставки по модулю
-		sort.SliceStable(exchangeRates, func(i, j int) bool {
-			return math.Abs(exchangeRates[i].Rate) > math.Abs(exchangeRates[j].Rate)
-		})
-
-		var formattedRates []string
-		var filteredCount int
-		for _, rate := range exchangeRates {
-			absRate := math.Abs(rate.Rate)
-			if absRate >= threshold {
-				log.Printf("[%s] %s: rate=%.6f%%, threshold=%.6f%%, проходит фильтр",
-					exchangeName, rate.Symbol, rate.Rate*100, threshold*100)
-				paymentTime := rate.NextFunding
-				if paymentTime != "Неизвестно" {
-					if t, err := time.Parse(time.RFC3339, rate.NextFunding); err == nil {
-						if exchangeName == "HTX" {
-							paymentTime = t.Format("02.01.2006 15:04")
-						} else {
-							paymentTime = t.Format("02.01.2006 15:04 MST")
-						}
-					}
-				}
-				payDirection := ""
-				payEmoji := ""
-				if rate.Rate > 0 {
-					payDirection = "Long → Short"
-					payEmoji = "⬆️"
-				} else if rate.Rate < 0 {
-					payDirection = "Short → Long"
-					payEmoji = "⬇️"
-				}
-				// Форматируем объемы
-				volumeInfo := ""
-				if rate.VolumeUSDT24h > 0 {
-					if rate.VolumeUSDT24h >= 1000000 {
-						volumeInfo = fmt.Sprintf(" | Vol: $%.1fM", rate.VolumeUSDT24h/1000000)
-					} else if rate.VolumeUSDT24h >= 1000 {
-						volumeInfo = fmt.Sprintf(" | Vol: $%.1fK", rate.VolumeUSDT24h/1000)
-					} else {
-						volumeInfo = fmt.Sprintf(" | Vol: $%.0f", rate.VolumeUSDT24h)
-					}
-				} else if rate.Volume24h > 0 {
-					if rate.Volume24h >= 1000000000 {
-						volumeInfo = fmt.Sprintf(" | Vol: %.1fB", rate.Volume24h/1000000000)
-					} else if rate.Volume24h >= 1000000 {
-						volumeInfo = fmt.Sprintf(" | Vol: %.1fM", rate.Volume24h/1000000)
-					} else if rate.Volume24h >= 1000 {
-						volumeInfo = fmt.Sprintf(" | Vol: %.1fK", rate.Volume24h/1000)
-					} else {
-						volumeInfo = fmt.Sprintf(" | Vol: %.0f", rate.Volume24h)
-					}
-				}
-				line := fmt.Sprintf("%-12s %+8.4f%%  %s  %-12s%s  (выплата: %s)",
-					rate.Symbol, rate.Rate*100, payEmoji, payDirection, volumeInfo, paymentTime)
-				formattedRates = append(formattedRates, line)
-			} else {
-				filteredCount++
-			}
+// sendLongMessageTo отправляет длинное сообщение частями; слишком длинное — одним документом
+func (b *Bot) sendLongMessageTo(target chatTarget, text string, priority sendPriority) {
+	parts := splitHTML(text, telegramTextLimit)
+	var msgs []*outgoingMessage
+	if len(parts) > maxMessageParts {
+		msgs = []*outgoingMessage{{
+			Target:   target,
+			Text:     tr(chatLang(target.ChatID), "message.as_document"),
+			File:     documentAttachment("message.txt", []byte(plainText(text))),
+			Priority: priority,
+		}}
+	} else {
+		for _, part := range parts {
+			msgs = append(msgs, &outgoingMessage{Target: target, Text: part, Priority: priority})
 		}
+	}
+	if _, err := b.deliver(msgs); err != nil {
+		log.Printf("Ошибка отправки сообщения в чат %d: %v", target.ChatID, err)
+	}
+}
 
-		log.Printf("Биржа %s: отфильтровано %d ставок из %d",
-			exchangeName, filteredCount, len(exchangeRates))
-
-		if len(formattedRates) > 0 {
-			result = append(result, fmt.Sprintf("\n<b>📈 %s</b>", exchangeName))
+// formatRateLine форматирует одну ставку для вывода в блоке <pre>
+func formatRateLine(rate exchanges.FundingRate, loc *time.Location, now time.Time, lang string) string {
+	paymentTime := tr(lang, "rates.unknown_time")
+	if t, ok := nextFundingTime(rate); ok {
+		paymentTime = formatFundingTime(t, loc, now, lang)
+	}
+	payDirection := ""
+	payEmoji := ""
+	if rate.Rate > 0 {
+		payDirection = "Long → Short"
+		payEmoji = "⬆️"
+	} else if rate.Rate < 0 {
+		payDirection = "Short → Long"
+		payEmoji = "⬇️"
+	}
+	// Форматируем объемы
+	volumeInfo := ""
+	if volume := formatVolume(rate); volume != "" {
+		volumeInfo = " | Vol: " + volume
+	}
+	return fmt.Sprintf("%-12s %+8.4f%%  %s  %-12s%s  %s",
+		rate.Symbol, rate.Rate*100, payEmoji, payDirection, volumeInfo, tr(lang, "rates.payment", paymentTime))
+}
 
-			maxRates := 20
-			ratesToShow := formattedRates
-			if len(formattedRates) > maxRates {
-				ratesToShow = formattedRates[:maxRates]
-				result = append(result, "<pre>"+strings.Join(ratesToShow, "\n")+"</pre>")
-				result = append(result, fmt.Sprintf("<i>... и еще %d записей</i>", len(formattedRates)-maxRates))
-			} else {
-				result = append(result, "<pre>"+strings.Join(ratesToShow, "\n")+"</pre>")
-			}
-			result = append(result, "────────────────────────────")
+// formatVolume форматирует суточный объём: в долларах, если биржа отдаёт объём в USDT,
+// иначе в контрактах/монетах
+func formatVolume(rate exchanges.FundingRate) string {
+	if rate.VolumeUSDT24h > 0 {
+		if rate.VolumeUSDT24h >= 1000000 {
+			return fmt.Sprintf("$%.1fM", rate.VolumeUSDT24h/1000000)
+		} else if rate.VolumeUSDT24h >= 1000 {
+			return fmt.Sprintf("$%.1fK", rate.VolumeUSDT24h/1000)
 		}
+		return fmt.Sprintf("$%.0f", rate.VolumeUSDT24h)
+	} else if rate.Volume24h > 0 {
+		if rate.Volume24h >= 1000000000 {
+			return fmt.Sprintf("%.1fB", rate.Volume24h/1000000000)
+		} else if rate.Volume24h >= 1000000 {
+			return fmt.Sprintf("%.1fM", rate.Volume24h/1000000)
+		} else if rate.Volume24h >= 1000 {
+			return fmt.Sprintf("%.1fK", rate.Volume24h/1000)
+		}
+		return fmt.Sprintf("%.0f", rate.Volume24h)
 	}
-
-	if len(result) == 0 {
-		return "<i>Нет доступных ставок фандинга, превышающих порог</i>"
-	}
-
-	return strings.Join(result, "\n")
+	return ""
 }
 
-// handleThreshold обрабатывает команду установки порога
-func (b *Bot) handleThreshold(msg *tgbotapi.Message) {
-	log.Printf("Обработка команды threshold от пользователя %s", msg.From.UserName)
+// handleThreshold обрабатывает команду установки порога:
+// /threshold 0.1%, /threshold +0.05% -0.2%, /threshold positive|negative|both
+func (b *Bot) handleThreshold(r *request) {
+	msg := r.Msg
+	log.Printf("Обработка команды threshold от пользователя %s", senderName(msg))
+
+	lang := msgLang(msg)
 
 	// Получаем аргумент команды
-	args := strings.TrimSpace(msg.CommandArguments())
-	if args == "" {
+	if len(r.Args) == 0 {
 		// Если аргумент не указан, показываем текущий порог
-		threshold, source := getUserThreshold(msg.Chat.ID)
-		response := fmt.Sprintf("Текущий порог: %.3f%% (источник: %s)\nДля установки нового порога используйте команду /threshold X.XXX",
-			threshold*100, source)
-		b.sendLongMessage(msg.Chat.ID, response)
+		_, source := getUserThreshold(msg.Chat.ID)
+		response := tr(lang, "threshold.current",
+			getRateThresholds(msg.Chat.ID).describe(lang), tr(lang, "threshold.source."+source))
+		b.reply(msg, response)
 		return
 	}
 
-	// Убираем знак процента, если он есть
-	args = strings.TrimSuffix(args, "%")
-
-	// Парсим новое значение
-	threshold, err := strconv.ParseFloat(args, 64)
-	if err != nil {
-		b.sendLongMessage(msg.Chat.ID, "Ошибка: укажите корректное число, например: /threshold 0.1")
+	if !b.canChangeSettings(msg) {
+		b.reply(msg, tr(lang, "threshold.admin_only"))
 		return
 	}
 
-	// Проверяем диапазон
-	if threshold <= 0 {
-		b.sendLongMessage(msg.Chat.ID, "Ошибка: порог должен быть положительным числом")
+	update, err := parseThresholdArgs(r.Args)
+	if err != nil {
+		b.reply(msg, tr(lang, "threshold.error", escapeHTML(localize(lang, err))))
 		return
 	}
 
-	// Если значение указано в процентах (больше 1), конвертируем в десятичную дробь
-	if threshold > 1 {
-		threshold = threshold / 100
+	// Общий порог задаёт оба направления, отдельные значения уточняют их.
+	// Без нового положительного порога чат продолжает следовать порогу по умолчанию.
+	if update.Positive > 0 {
+		setUserThreshold(msg.Chat.ID, update.Positive)
+	} else if update.Both > 0 {
+		setUserThreshold(msg.Chat.ID, update.Both)
 	}
 
-	// Устанавливаем новый порог
-	setUserThreshold(msg.Chat.ID, threshold)
+	updateChatSettings(msg.Chat.ID, func(settings *ChatSettings) {
+		if update.Both > 0 {
+			settings.NegativeThreshold = 0
+		}
+		if update.Negative > 0 {
+			settings.NegativeThreshold = update.Negative
+		}
+		if update.SetDirection {
+			settings.Direction = update.Direction
+		}
+	})
 
-	response := fmt.Sprintf("Установлен новый порог: %.3f%%", threshold*100)
-	b.sendLongMessage(msg.Chat.ID, response)
+	response := tr(lang, "threshold.set", getRateThresholds(msg.Chat.ID).describe(lang))
+	b.reply(msg, response)
 
 	// Сразу показываем ставки с новым порогом
-	go b.handleRates(msg)
+	b.handleRates(r)
 }
@@ -0,0 +1,539 @@
+package bot
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	chartWidth  = 1000
+	chartHeight = 560
+	// chartTextScale масштаб шрифта 5×7 для подписей
+	chartTextScale = 2
+	chartLineWidth = 2
+	minMarkerGap   = 12
+
+	defaultChartPeriod = 7 * 24 * time.Hour
+)
+
+var (
+	chartBackground = color.RGBA{255, 255, 255, 255}
+	chartGrid       = color.RGBA{228, 228, 228, 255}
+	chartAxis       = color.RGBA{150, 150, 150, 255}
+	chartText       = color.RGBA{60, 60, 60, 255}
+	chartZero       = color.RGBA{0, 0, 0, 255}
+)
+
+// chartPalette цвета линий в порядке легенды
+var chartPalette = []color.RGBA{
+	{31, 119, 180, 255},
+	{255, 127, 14, 255},
+	{44, 160, 44, 255},
+	{214, 39, 40, 255},
+	{148, 103, 189, 255},
+	{140, 86, 75, 255},
+	{227, 119, 194, 255},
+	{127, 127, 127, 255},
+	{188, 189, 34, 255},
+	{23, 190, 207, 255},
+}
+
+// chartPoint значение ряда в момент времени
+type chartPoint struct {
+	Time  time.Time
+	Value float64
+}
+
+// chartSeries линия графика; Markers рисуются кружками поверх линии
+type chartSeries struct {
+	Label   string
+	Points  []chartPoint
+	Markers []chartPoint
+}
+
+// lineChart линейный график ставок за период [From, To]. Подписи только латиницей:
+// растровый шрифт не содержит кириллицы.
+type lineChart struct {
+	Title string
+	From  time.Time
+	To    time.Time
+	Loc   *time.Location
+	// MaxGap точки, между которыми больше MaxGap, не соединяются: в истории пропуск
+	MaxGap time.Duration
+	// MarkerLabel подпись маркеров в легенде
+	MarkerLabel string
+	Series      []chartSeries
+}
+
+// plotArea область построения графика внутри полей
+type plotArea struct {
+	left, top, right, bottom int
+	from, to                 time.Time
+	min, max                 float64
+}
+
+func (p plotArea) x(t time.Time) int {
+	return p.left + int(float64(p.right-p.left)*float64(t.Sub(p.from))/float64(p.to.Sub(p.from)))
+}
+
+func (p plotArea) y(v float64) int {
+	return p.bottom - int(float64(p.bottom-p.top)*(v-p.min)/(p.max-p.min))
+}
+
+// render рисует график и кодирует его в PNG
+func (c lineChart) render() ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, chartWidth, chartHeight))
+	fillRect(img, 0, 0, chartWidth, chartHeight, chartBackground)
+
+	lineHeight := (glyphHeight + 4) * chartTextScale
+	legend := c.legendRows(chartWidth - 40)
+	area := plotArea{
+		left:   130,
+		top:    20 + lineHeight*2,
+		right:  chartWidth - 30,
+		bottom: chartHeight - 20 - lineHeight*(len(legend)+1),
+		from:   c.From,
+		to:     c.To,
+	}
+	ticks := c.valueTicks(&area)
+
+	drawText(img, area.left, 20, c.Title, chartTextScale, chartText)
+
+	// Горизонтальная сетка и подписи значений
+	for _, tick := range ticks {
+		y := area.y(tick.Value)
+		drawLine(img, area.left, y, area.right, y, 1, chartGrid)
+		label := tick.Label
+		drawText(img, area.left-10-textWidth(label, chartTextScale), y-glyphHeight*chartTextScale/2, label, chartTextScale, chartText)
+	}
+
+	// Вертикальная сетка и подписи времени
+	for _, tick := range c.timeTicks() {
+		x := area.x(tick.Time)
+		drawLine(img, x, area.top, x, area.bottom, 1, chartGrid)
+		// Подписи у краёв наезжали бы на шкалу значений или не помещались
+		label := tick.Label
+		if left := x - textWidth(label, chartTextScale)/2; left > area.left-20 && left+textWidth(label, chartTextScale) < chartWidth {
+			drawText(img, left, area.bottom+8, label, chartTextScale, chartText)
+		}
+	}
+
+	drawRect(img, area.left, area.top, area.right, area.bottom, chartAxis)
+	zero := area.y(0)
+	drawLine(img, area.left, zero, area.right, zero, chartLineWidth, chartZero)
+
+	for i, series := range c.Series {
+		lineColor := chartPalette[i%len(chartPalette)]
+		for j := 1; j < len(series.Points); j++ {
+			prev, cur := series.Points[j-1], series.Points[j]
+			if c.MaxGap > 0 && cur.Time.Sub(prev.Time) > c.MaxGap {
+				continue
+			}
+			drawLine(img, area.x(prev.Time), area.y(prev.Value), area.x(cur.Time), area.y(cur.Value), chartLineWidth, lineColor)
+		}
+		if len(series.Points) == 1 {
+			p := series.Points[0]
+			fillCircle(img, area.x(p.Time), area.y(p.Value), chartLineWidth, lineColor)
+		}
+		// На длинных периодах выплаты раз в час сливаются, рисуем маркеры не чаще чем через minMarkerGap пикселей
+		lastMarker := -minMarkerGap
+		for _, marker := range series.Markers {
+			x := area.x(marker.Time)
+			if x-lastMarker < minMarkerGap {
+				continue
+			}
+			drawMarker(img, x, area.y(marker.Value), lineColor)
+			lastMarker = x
+		}
+	}
+
+	// Легенда под осью времени
+	y := area.bottom + 8 + lineHeight + 8
+	for _, row := range legend {
+		x := 20
+		for _, item := range row {
+			if item.marker {
+				drawMarker(img, x+6, y+glyphHeight*chartTextScale/2, chartText)
+			} else {
+				fillRect(img, x, y, 12, 12, chartPalette[item.index%len(chartPalette)])
+			}
+			drawText(img, x+20, y, item.label, chartTextScale, chartText)
+			x += item.width
+		}
+		y += lineHeight
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// legendItem элемент легенды: цвет ряда или значок маркера
+type legendItem struct {
+	label  string
+	index  int
+	marker bool
+	width  int
+}
+
+// legendRows раскладывает элементы легенды по строкам шириной не больше width
+func (c lineChart) legendRows(width int) [][]legendItem {
+	var items []legendItem
+	for i, series := range c.Series {
+		items = append(items, legendItem{label: series.Label, index: i})
+	}
+	if c.MarkerLabel != "" {
+		items = append(items, legendItem{label: c.MarkerLabel, marker: true})
+	}
+
+	var rows [][]legendItem
+	var row []legendItem
+	used := 0
+	for _, item := range items {
+		item.width = 20 + textWidth(item.label, chartTextScale) + 24
+		if used+item.width > width && len(row) > 0 {
+			rows = append(rows, row)
+			row, used = nil, 0
+		}
+		row = append(row, item)
+		used += item.width
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// valueTick подпись на оси значений
+type valueTick struct {
+	Value float64
+	Label string
+}
+
+// valueTicks подбирает «круглый» шаг оси значений и задаёт её границы; ноль всегда на графике
+func (c lineChart) valueTicks(area *plotArea) []valueTick {
+	minValue, maxValue := 0.0, 0.0
+	for _, series := range c.Series {
+		for _, p := range series.Points {
+			minValue = math.Min(minValue, p.Value)
+			maxValue = math.Max(maxValue, p.Value)
+		}
+	}
+	if maxValue-minValue < 1e-6 {
+		minValue, maxValue = minValue-1e-4, maxValue+1e-4
+	}
+
+	step := niceStep((maxValue - minValue) / 5)
+	area.min = math.Floor(minValue/step) * step
+	area.max = math.Ceil(maxValue/step) * step
+
+	// Знаков после запятой столько, чтобы шаг в процентах был различим
+	decimals := int(math.Max(0, -math.Floor(math.Log10(step*100))))
+	var ticks []valueTick
+	for v := area.min; v <= area.max+step/2; v += step {
+		label := "0%"
+		if math.Abs(v) > step/2 {
+			label = strconv.FormatFloat(v*100, 'f', decimals, 64) + "%"
+		}
+		ticks = append(ticks, valueTick{Value: v, Label: label})
+	}
+	return ticks
+}
+
+// niceStep округляет шаг до 1, 2 или 5 × 10^n
+func niceStep(raw float64) float64 {
+	exp := math.Pow(10, math.Floor(math.Log10(raw)))
+	switch f := raw / exp; {
+	case f <= 1:
+		return exp
+	case f <= 2:
+		return 2 * exp
+	case f <= 5:
+		return 5 * exp
+	}
+	return 10 * exp
+}
+
+// timeTick подпись на оси времени
+type timeTick struct {
+	Time  time.Time
+	Label string
+}
+
+// timeTicks подписи оси времени в поясе графика: даты для периодов от двух суток, иначе часы
+func (c lineChart) timeTicks() []timeTick {
+	const maxTicks = 10
+	span := c.To.Sub(c.From)
+	from := c.From.In(c.Loc)
+	var ticks []timeTick
+
+	if span >= 48*time.Hour {
+		days := 1
+		for _, step := range []int{1, 2, 3, 5, 7, 14, 30} {
+			days = step
+			if span/(time.Duration(step)*24*time.Hour) < maxTicks {
+				break
+			}
+		}
+		for i := 1; ; i++ {
+			t := time.Date(from.Year(), from.Month(), from.Day()+i, 0, 0, 0, 0, c.Loc)
+			if t.After(c.To) {
+				break
+			}
+			if t.YearDay()%days == 0 || days == 1 {
+				ticks = append(ticks, timeTick{Time: t, Label: t.Format("02.01")})
+			}
+		}
+		return ticks
+	}
+
+	hours := 1
+	for _, step := range []int{1, 2, 3, 6, 12} {
+		hours = step
+		if span/(time.Duration(step)*time.Hour) < maxTicks {
+			break
+		}
+	}
+	t := time.Date(from.Year(), from.Month(), from.Day(), from.Hour(), 0, 0, 0, c.Loc)
+	for ; !t.After(c.To); t = t.Add(time.Hour) {
+		if t.Before(c.From) || t.Hour()%hours != 0 {
+			continue
+		}
+		ticks = append(ticks, timeTick{Time: t, Label: t.Format("15:04")})
+	}
+	return ticks
+}
+
+// fillRect закрашивает прямоугольник с левым верхним углом (x, y)
+func fillRect(img *image.RGBA, x, y, w, h int, c color.Color) {
+	bounds := img.Bounds()
+	for py := y; py < y+h; py++ {
+		for px := x; px < x+w; px++ {
+			if (image.Point{px, py}).In(bounds) {
+				img.Set(px, py, c)
+			}
+		}
+	}
+}
+
+// drawRect рисует рамку прямоугольника
+func drawRect(img *image.RGBA, x0, y0, x1, y1 int, c color.Color) {
+	drawLine(img, x0, y0, x1, y0, 1, c)
+	drawLine(img, x0, y1, x1, y1, 1, c)
+	drawLine(img, x0, y0, x0, y1, 1, c)
+	drawLine(img, x1, y0, x1, y1, 1, c)
+}
+
+// drawLine рисует отрезок толщиной width алгоритмом Брезенхэма
+func drawLine(img *image.RGBA, x0, y0, x1, y1, width int, c color.Color) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	offset := width / 2
+	for e := dx + dy; ; {
+		fillRect(img, x0-offset, y0-offset, width, width, c)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			e += dx
+			y0 += sy
+		}
+	}
+}
+
+// fillCircle рисует закрашенный круг радиуса r
+func fillCircle(img *image.RGBA, cx, cy, r int, c color.Color) {
+	for y := -r; y <= r; y++ {
+		for x := -r; x <= r; x++ {
+			if x*x+y*y <= r*r {
+				fillRect(img, cx+x, cy+y, 1, 1, c)
+			}
+		}
+	}
+}
+
+// drawMarker рисует маркер выплаты — кольцо цвета ряда
+func drawMarker(img *image.RGBA, cx, cy int, c color.Color) {
+	fillCircle(img, cx, cy, 5, c)
+	fillCircle(img, cx, cy, 2, chartBackground)
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// historySeries строит ряды графика из истории: по линии на контракт, ставки в пересчёте на 8 часов.
+// Выплата отмечается там, где время следующего фандинга сдвинулось вперёд, значением последнего снимка перед ней;
+// выплаты внутри пропусков истории длиннее maxGap не отмечаются.
+func historySeries(points []historyPoint, maxGap time.Duration) []chartSeries {
+	type seriesKey struct{ exchange, symbol string }
+	grouped := make(map[seriesKey][]historyPoint)
+	contracts := make(map[string]int)
+	for _, p := range points {
+		key := seriesKey{p.Exchange, p.Symbol}
+		if _, ok := grouped[key]; !ok {
+			contracts[p.Exchange]++
+		}
+		grouped[key] = append(grouped[key], p)
+	}
+
+	keys := make([]seriesKey, 0, len(grouped))
+	for key := range grouped {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].exchange != keys[j].exchange {
+			return keys[i].exchange < keys[j].exchange
+		}
+		return keys[i].symbol < keys[j].symbol
+	})
+
+	series := make([]chartSeries, 0, len(keys))
+	for _, key := range keys {
+		label := key.exchange
+		if contracts[key.exchange] > 1 {
+			label += " " + key.symbol
+		}
+		s := chartSeries{Label: label}
+		history := grouped[key]
+		for i, p := range history {
+			value := normalizedRate8h(p.Exchange, p.Rate)
+			s.Points = append(s.Points, chartPoint{Time: p.Time, Value: value})
+			if i+1 < len(history) {
+				next := history[i+1]
+				if !p.NextFunding.IsZero() && next.NextFunding.After(p.NextFunding) && !p.NextFunding.After(next.Time) &&
+					next.Time.Sub(p.Time) <= maxGap {
+					s.Markers = append(s.Markers, chartPoint{Time: p.NextFunding, Value: value})
+				}
+			}
+		}
+		series = append(series, s)
+	}
+	return series
+}
+
+// chartOptions период и биржи графика
+type chartOptions struct {
+	Period      time.Duration
+	PeriodLabel string
+	Filter      rateFilter
+}
+
+// parseChartOptions разбирает аргументы графика после тикера: период (7d, 24h) и биржи через запятую
+func parseChartOptions(args []string) (chartOptions, error) {
+	opts := chartOptions{Period: defaultChartPeriod, PeriodLabel: "7d"}
+	for _, arg := range args {
+		if value, err := parsePeriod(arg); err == nil {
+			opts.Period, opts.PeriodLabel = value, strings.ToLower(arg)
+			continue
+		}
+		if err := opts.Filter.addExchanges(arg); err != nil {
+			return opts, err
+		}
+	}
+	return opts, nil
+}
+
+// symbolHistory читает историю ставок монеты за период до now по биржам из фильтра
+func (b *Bot) symbolHistory(base string, opts chartOptions, now time.Time) ([]historyPoint, error) {
+	// Тикеры повторяются в каждом снимке, нормализуем каждый один раз
+	matched := make(map[string]bool)
+	return b.history.query(now.Add(-opts.Period), now, func(exchangeName, symbol string) bool {
+		if !opts.Filter.matchExchange(exchangeName) {
+			return false
+		}
+		ok, seen := matched[symbol]
+		if !seen {
+			ok = normalizeSymbol(symbol) == base
+			matched[symbol] = ok
+		}
+		return ok
+	})
+}
+
+// symbolChart график истории ставок монеты
+func (b *Bot) symbolChart(base string, opts chartOptions, points []historyPoint, loc *time.Location, now time.Time) lineChart {
+	maxGap := 3 * b.history.interval
+	return lineChart{
+		Title:       fmt.Sprintf("%s FUNDING, %% PER 8H, %s (%s)", base, opts.PeriodLabel, loc),
+		From:        now.Add(-opts.Period),
+		To:          now,
+		Loc:         loc,
+		MaxGap:      maxGap,
+		MarkerLabel: "SETTLEMENT",
+		Series:      historySeries(points, maxGap),
+	}
+}
+
+// handleChart рисует график истории ставок монеты: /chart BTC 7d binance,bybit
+func (b *Bot) handleChart(r *request) {
+	msg := r.Msg
+	lang := msgLang(msg)
+	args := r.Args
+	if len(args) == 0 {
+		b.reply(msg, tr(lang, "chart.usage"))
+		return
+	}
+
+	base := normalizeSymbol(args[0])
+	opts, err := parseChartOptions(args[1:])
+	if err == nil {
+		err = opts.Filter.validateExchanges(b.exchanges)
+	}
+	if err != nil {
+		b.reply(msg, tr(lang, "error", escapeHTML(localize(lang, err))))
+		return
+	}
+	if opts.Period > b.history.retention {
+		b.reply(msg, tr(lang, "history.too_long", formatDuration(b.history.retention, lang)))
+		return
+	}
+
+	now := time.Now()
+	points, err := b.symbolHistory(base, opts, now)
+	if err != nil {
+		log.Printf("Ошибка чтения истории для /chart %s: %v", base, err)
+		b.reply(msg, tr(lang, "chart.error"))
+		return
+	}
+	if len(points) == 0 {
+		b.reply(msg, tr(lang, "chart.no_data", escapeHTML(base), formatDuration(b.history.interval, lang)))
+		return
+	}
+
+	loc := chatLocation(msg.Chat.ID)
+	data, err := b.symbolChart(base, opts, points, loc, now).render()
+	if err != nil {
+		log.Printf("Ошибка построения графика %s: %v", base, err)
+		b.reply(msg, tr(lang, "chart.error"))
+		return
+	}
+
+	caption := tr(lang, "chart.caption", escapeHTML(base), formatDuration(opts.Period, lang), escapeHTML(loc.String()))
+	b.replyFile(msg, photoAttachment(strings.ToLower(base)+"_funding.png", data), caption)
+}
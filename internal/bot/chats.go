@@ -0,0 +1,241 @@
+package bot
+
+import (
+	"log"
+	"sync"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// ChatSettings настройки отдельного чата: личного, группы или канала
+type ChatSettings struct {
+	Type  string `json:"type,omitempty"`
+	Title string `json:"title,omitempty"`
+	// ThreadID тема форума, в которую идёт рассылка (0 — основной чат)
+	ThreadID int `json:"thread_id,omitempty"`
+	// MinVolume минимальный суточный объём в долларах для /rates и рассылок
+	MinVolume float64 `json:"min_volume,omitempty"`
+	// NegativeThreshold порог для отрицательных ставок (0 — такой же, как для положительных)
+	NegativeThreshold float64 `json:"negative_threshold,omitempty"`
+	// Direction показывать только положительные (positive) или отрицательные (negative) ставки
+	Direction string `json:"direction,omitempty"`
+	// Timezone часовой пояс чата для расписания рассылки (пусто — TIMEZONE)
+	Timezone string `json:"timezone,omitempty"`
+	// EveryMinutes период рассылки в минутах (0 — по умолчанию, 5 минут)
+	EveryMinutes int `json:"every_minutes,omitempty"`
+	// QuietStart, QuietEnd тихие часы по местному времени чата, ЧЧ:ММ
+	QuietStart string `json:"quiet_start,omitempty"`
+	QuietEnd   string `json:"quiet_end,omitempty"`
+	// Digest время дайджестов ЧЧ:ММ; если задано, ставки приходят только в это время
+	Digest []string `json:"digest,omitempty"`
+	// Live живое табло (/live): закреплённое сообщение LiveMessageID в теме LiveThreadID,
+	// которое бот редактирует после каждого обновления ставок
+	Live          bool `json:"live,omitempty"`
+	LiveMessageID int  `json:"live_message_id,omitempty"`
+	LiveThreadID  int  `json:"live_thread_id,omitempty"`
+	// Lang язык, выбранный через /lang; DetectedLang — определённый по language_code собеседника
+	Lang         string `json:"lang,omitempty"`
+	DetectedLang string `json:"detected_lang,omitempty"`
+}
+
+// chatTarget адрес доставки сообщения: чат и тема форума
+type chatTarget struct {
+	ChatID   int64
+	ThreadID int
+}
+
+var (
+	chatSettings     = make(map[int64]*ChatSettings)
+	chatSettingsLock sync.Mutex
+)
+
+// getChatSettings возвращает копию настроек чата
+func getChatSettings(chatID int64) ChatSettings {
+	chatSettingsLock.Lock()
+	defer chatSettingsLock.Unlock()
+	if settings, ok := chatSettings[chatID]; ok {
+		return *settings
+	}
+	return ChatSettings{}
+}
+
+// updateChatSettings изменяет настройки чата и сохраняет их в файл
+func updateChatSettings(chatID int64, update func(settings *ChatSettings)) {
+	chatSettingsLock.Lock()
+	settings, ok := chatSettings[chatID]
+	if !ok {
+		settings = &ChatSettings{}
+		chatSettings[chatID] = settings
+	}
+	update(settings)
+	chatSettingsLock.Unlock()
+	saveSettings()
+}
+
+// rememberChat запоминает тип и название чата
+func rememberChat(chat *tgbotapi.Chat) {
+	title := chat.Title
+	if title == "" {
+		title = chat.UserName
+	}
+
+	chatSettingsLock.Lock()
+	settings, ok := chatSettings[chat.ID]
+	changed := !ok || settings.Type != chat.Type || settings.Title != title
+	chatSettingsLock.Unlock()
+
+	if changed {
+		updateChatSettings(chat.ID, func(settings *ChatSettings) {
+			settings.Type = chat.Type
+			settings.Title = title
+		})
+	}
+}
+
+// forgetChat удаляет подписку и все настройки чата после удаления бота из группы или канала
+func forgetChat(chatID int64) {
+	subscribersLock.Lock()
+	delete(subscribers, chatID)
+	subscribersLock.Unlock()
+
+	userThresholdsLock.Lock()
+	delete(userThresholds, chatID)
+	userThresholdsLock.Unlock()
+
+	chatSettingsLock.Lock()
+	delete(chatSettings, chatID)
+	chatSettingsLock.Unlock()
+
+	saveSettings()
+}
+
+// dropDelivery отписывает чат, куда бот больше не может писать, и выключает в нём табло.
+// Остальные настройки остаются: пользователь мог заблокировать бота на время, а в группе —
+// временно лишить его прав. Группы и каналы очищает forgetChat, когда бота из них удаляют.
+func dropDelivery(chatID int64) {
+	subscribersLock.Lock()
+	delete(subscribers, chatID)
+	subscribersLock.Unlock()
+
+	chatSettingsLock.Lock()
+	if settings, ok := chatSettings[chatID]; ok {
+		settings.Live = false
+		settings.LiveMessageID = 0
+		settings.LiveThreadID = 0
+	}
+	chatSettingsLock.Unlock()
+
+	saveSettings()
+}
+
+// migrateChat переносит подписку и настройки группы, преобразованной в супергруппу
+func migrateChat(fromID, toID int64) {
+	subscribersLock.Lock()
+	if _, ok := subscribers[fromID]; ok {
+		delete(subscribers, fromID)
+		subscribers[toID] = struct{}{}
+	}
+	subscribersLock.Unlock()
+
+	userThresholdsLock.Lock()
+	if t, ok := userThresholds[fromID]; ok {
+		delete(userThresholds, fromID)
+		userThresholds[toID] = t
+	}
+	userThresholdsLock.Unlock()
+
+	approvedChatsLock.Lock()
+	if _, ok := approvedChats[fromID]; ok {
+		delete(approvedChats, fromID)
+		approvedChats[toID] = struct{}{}
+	}
+	approvedChatsLock.Unlock()
+
+	chatSettingsLock.Lock()
+	if settings, ok := chatSettings[fromID]; ok {
+		delete(chatSettings, fromID)
+		settings.Type = "supergroup"
+		chatSettings[toID] = settings
+	}
+	chatSettingsLock.Unlock()
+
+	saveSettings()
+	log.Printf("Чат %d преобразован в супергруппу %d, настройки перенесены", fromID, toID)
+}
+
+// targetFor возвращает адрес рассылки для чата с учётом выбранной темы форума
+func targetFor(chatID int64) chatTarget {
+	return chatTarget{ChatID: chatID, ThreadID: getChatSettings(chatID).ThreadID}
+}
+
+// senderName возвращает имя отправителя для логов; у постов каналов From == nil
+func senderName(msg *tgbotapi.Message) string {
+	if msg.From != nil {
+		if msg.From.UserName != "" {
+			return msg.From.UserName
+		}
+		return msg.From.FirstName
+	}
+	if msg.SenderChat != nil {
+		return msg.SenderChat.Title
+	}
+	return msg.Chat.Title
+}
+
+// canChangeSettings проверяет, может ли отправитель менять настройки чата.
+// В группах это разрешено только администраторам группы и администраторам бота.
+func (b *Bot) canChangeSettings(msg *tgbotapi.Message) bool {
+	if msg.Chat.IsPrivate() || msg.Chat.IsChannel() || isAdmin(msg) {
+		return true
+	}
+	// Анонимный администратор пишет от имени самой группы
+	if msg.SenderChat != nil && msg.SenderChat.ID == msg.Chat.ID {
+		return true
+	}
+	if msg.From == nil {
+		return false
+	}
+
+	member, err := b.bot.GetChatMember(tgbotapi.GetChatMemberConfig{
+		ChatConfigWithUser: tgbotapi.ChatConfigWithUser{
+			ChatID: msg.Chat.ID,
+			UserID: msg.From.ID,
+		},
+	})
+	if err != nil {
+		log.Printf("Ошибка проверки прав пользователя %d в чате %d: %v", msg.From.ID, msg.Chat.ID, err)
+		return false
+	}
+	return member.IsCreator() || member.IsAdministrator()
+}
+
+// handleMyChatMember обрабатывает добавление и удаление бота из групп и каналов и блокировку в личных чатах
+func (b *Bot) handleMyChatMember(update *tgbotapi.ChatMemberUpdated) {
+	defer logPanic("обработке статуса бота в чате")
+
+	chat := update.Chat
+	status := update.NewChatMember.Status
+	log.Printf("Статус бота в чате %d (%s) изменился: %s → %s",
+		chat.ID, chat.Type, update.OldChatMember.Status, status)
+
+	switch {
+	case status == "kicked" && chat.IsPrivate():
+		// Пользователь заблокировал бота: после разблокировки настройки пригодятся снова
+		dropDelivery(chat.ID)
+		log.Printf("Пользователь %d заблокировал бота, подписка удалена", chat.ID)
+	case status == "left" || status == "kicked":
+		forgetChat(chat.ID)
+		log.Printf("Бот удалён из чата %d, подписка и настройки очищены", chat.ID)
+	case status == "member" || status == "administrator":
+		rememberChat(&chat)
+		wasOutside := update.OldChatMember.HasLeft() || update.OldChatMember.WasKicked()
+		if !wasOutside || chat.IsPrivate() {
+			return
+		}
+		// В канал бот может писать только как администратор
+		if chat.IsChannel() && status != "administrator" {
+			return
+		}
+		b.sendLongMessage(chat.ID, tr(userLang(chat.ID, &update.From), "chat.welcome"))
+	}
+}
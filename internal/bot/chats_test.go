@@ -0,0 +1,85 @@
+package bot
+
+import (
+	"path/filepath"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// useTestSettings подменяет файл настроек временным и очищает подписки и настройки чатов на время теста
+func useTestSettings(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	oldSettingsFile, oldLastSentFile := settingsFile, lastSentFile
+	settingsFile = filepath.Join(dir, "settings.json")
+	lastSentFile = filepath.Join(dir, "last_sent.json")
+	subscribers = make(map[int64]struct{})
+	userThresholds = make(map[int64]float64)
+	chatSettings = make(map[int64]*ChatSettings)
+	t.Cleanup(func() {
+		settingsFile, lastSentFile = oldSettingsFile, oldLastSentFile
+		subscribers = make(map[int64]struct{})
+		userThresholds = make(map[int64]float64)
+		chatSettings = make(map[int64]*ChatSettings)
+	})
+}
+
+// subscribeTestChat подписывает чат с собственным порогом и часовым поясом
+func subscribeTestChat(chatID int64) {
+	subscribers[chatID] = struct{}{}
+	userThresholds[chatID] = 0.002
+	chatSettings[chatID] = &ChatSettings{Timezone: "Europe/Kyiv", Live: true, LiveMessageID: 42}
+}
+
+func botStatusUpdate(chat tgbotapi.Chat, oldStatus, newStatus string) *tgbotapi.ChatMemberUpdated {
+	return &tgbotapi.ChatMemberUpdated{
+		Chat:          chat,
+		OldChatMember: tgbotapi.ChatMember{Status: oldStatus},
+		NewChatMember: tgbotapi.ChatMember{Status: newStatus},
+	}
+}
+
+func TestBlockedInPrivateChatKeepsSettings(t *testing.T) {
+	useTestSettings(t)
+	const chatID = 1001
+	subscribeTestChat(chatID)
+
+	b := &Bot{}
+	b.handleMyChatMember(botStatusUpdate(tgbotapi.Chat{ID: chatID, Type: "private"}, "member", "kicked"))
+
+	if _, ok := subscribers[chatID]; ok {
+		t.Error("заблокировавший бота чат должен быть отписан")
+	}
+	if threshold, ok := userThresholds[chatID]; !ok || threshold != 0.002 {
+		t.Errorf("порог чата должен сохраниться, получено %v, %v", threshold, ok)
+	}
+	settings, ok := chatSettings[chatID]
+	if !ok || settings.Timezone != "Europe/Kyiv" {
+		t.Fatalf("настройки чата должны сохраниться, получено %+v", settings)
+	}
+	if settings.Live || settings.LiveMessageID != 0 {
+		t.Errorf("табло должно выключиться, получено %+v", settings)
+	}
+}
+
+func TestRemovedFromGroupForgetsSettings(t *testing.T) {
+	useTestSettings(t)
+	b := &Bot{}
+	for _, status := range []string{"left", "kicked"} {
+		const chatID = -1002
+		subscribeTestChat(chatID)
+
+		b.handleMyChatMember(botStatusUpdate(tgbotapi.Chat{ID: chatID, Type: "supergroup"}, "member", status))
+
+		if _, ok := subscribers[chatID]; ok {
+			t.Errorf("%s: группа должна быть отписана", status)
+		}
+		if _, ok := userThresholds[chatID]; ok {
+			t.Errorf("%s: порог группы должен удалиться", status)
+		}
+		if _, ok := chatSettings[chatID]; ok {
+			t.Errorf("%s: настройки группы должны удалиться", status)
+		}
+	}
+}
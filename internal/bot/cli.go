@@ -0,0 +1,433 @@
+package bot
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	exchanges "github.com/petrixs/cr-exchanges"
+	"github.com/petrixs/cr-transport-bus/proto"
+)
+
+// Функции для разового запуска из командной строки: работают без Telegram и RabbitMQ.
+
+// Export записывает выгрузку ставок в w — то же, что /export, для запуска из командной строки.
+// Аргументы: csv|json [биржи] [период]. Без периода текущие ставки запрашиваются у бирж напрямую.
+func Export(w io.Writer, exs []exchanges.Exchange, args []string) error {
+	req, err := parseExportArgs(args)
+	if err != nil {
+		return err
+	}
+	if err := req.Filter.validateExchanges(exs); err != nil {
+		return err
+	}
+
+	var rates map[string][]exchanges.FundingRate
+	if req.Period == 0 {
+		rates = fetchRates(exs, req.Filter)
+	}
+	rows, err := exportRows(req, rates, newRateHistory(), time.Now())
+	if err != nil {
+		return err
+	}
+	return writeExport(w, req.Format, rows)
+}
+
+// Scan печатает текущие ставки выше порога, по убыванию ставки в пересчёте на 8 часов:
+// scan --exchanges binance,bybit --min 0.05% --format table|json|csv
+func Scan(w io.Writer, exs []exchanges.Exchange, args []string) error {
+	flags := flag.NewFlagSet("scan", flag.ContinueOnError)
+	exchangesFlag := flags.String("exchanges", "", "биржи через запятую, по умолчанию все")
+	minFlag := flags.String("min", "", "минимальная ставка по модулю в процентах: 0.05 или 0.05%")
+	direction := flags.String("direction", "", "только positive или только negative ставки")
+	format := flags.String("format", "table", "формат вывода: table, json или csv")
+	limit := flags.Int("limit", 0, "сколько ставок вывести, 0 — все")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		return fmt.Errorf("лишние аргументы: %s", strings.Join(flags.Args(), " "))
+	}
+
+	var filter rateFilter
+	if *exchangesFlag != "" {
+		if err := filter.addExchanges(*exchangesFlag); err != nil {
+			return err
+		}
+	}
+	if err := filter.validateExchanges(exs); err != nil {
+		return err
+	}
+
+	var thresholds rateThresholds
+	if *minFlag != "" {
+		min, err := parseThresholdValue(*minFlag)
+		if err != nil {
+			return fmt.Errorf("некорректный --min %q: %v", *minFlag, err)
+		}
+		thresholds.Positive, thresholds.Negative = min, min
+	}
+	switch *direction {
+	case directionAny, directionPositive, directionNegative:
+		thresholds.Direction = *direction
+	default:
+		return fmt.Errorf("некорректный --direction %q, ожидается positive или negative", *direction)
+	}
+	if *format != "table" && *format != exportCSV && *format != exportJSON {
+		return fmt.Errorf("некорректный --format %q, ожидается table, json или csv", *format)
+	}
+
+	now := time.Now()
+	var rows []exportRow
+	for _, row := range currentExportRows(fetchRates(exs, filter), filter, now) {
+		if thresholds.pass(row.Rate) {
+			rows = append(rows, row)
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		return math.Abs(rows[i].Rate8h) > math.Abs(rows[j].Rate8h)
+	})
+	if *limit > 0 && len(rows) > *limit {
+		rows = rows[:*limit]
+	}
+
+	if *format != "table" {
+		return writeExport(w, *format, rows)
+	}
+	return writeScanTable(w, rows, defaultLocation(), now, defaultLang())
+}
+
+// writeScanTable печатает ставки таблицей с выровненными столбцами
+func writeScanTable(w io.Writer, rows []exportRow, loc *time.Location, now time.Time, lang string) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t\n", tr(lang, "col.exchange"), tr(lang, "col.symbol"),
+		tr(lang, "col.rate"), tr(lang, "col.rate8h"), tr(lang, "col.payment"), tr(lang, "col.volume"))
+	for _, row := range rows {
+		payment := "—"
+		if row.NextFunding != nil {
+			payment = formatFundingClock(*row.NextFunding, loc, now, lang)
+		}
+		volume := formatVolume(exchanges.FundingRate{Volume24h: row.Volume24h, VolumeUSDT24h: row.VolumeUSDT24h})
+		if volume == "" {
+			volume = "—"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%+.4f%%\t%+.4f%%\t%s\t%s\t\n",
+			row.Exchange, row.Symbol, row.Rate*100, row.Rate8h*100, payment, volume)
+	}
+	return tw.Flush()
+}
+
+// FundingMessages запрашивает ставки у всех бирж и преобразует их в сообщения для RabbitMQ —
+// для разовой публикации без бота
+func FundingMessages(exs []exchanges.Exchange) []*proto.FundingRate {
+	rates := fetchRates(exs, rateFilter{})
+	names := make([]string, 0, len(rates))
+	for name := range rates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var messages []*proto.FundingRate
+	for _, name := range names {
+		for _, rate := range rates[name] {
+			messages = append(messages, fundingMessage(name, rate))
+		}
+	}
+	return messages
+}
+
+// fetchRates запрашивает текущие ставки у бирж из фильтра; ошибки бирж пишутся в лог
+func fetchRates(exs []exchanges.Exchange, filter rateFilter) map[string][]exchanges.FundingRate {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	rates := make(map[string][]exchanges.FundingRate)
+	for _, ex := range exs {
+		if !filter.matchExchange(ex.GetName()) {
+			continue
+		}
+		wg.Add(1)
+		go func(exchange exchanges.Exchange) {
+			defer wg.Done()
+			exchangeRates, err := exchange.GetFundingRates()
+			if err != nil {
+				log.Printf("Ошибка получения ставок %s: %v", exchange.GetName(), err)
+				return
+			}
+			mu.Lock()
+			rates[exchange.GetName()] = exchangeRates
+			mu.Unlock()
+		}(ex)
+	}
+	wg.Wait()
+	return rates
+}
+
+// ConfigReport результат проверки настроек: по строке на параметр
+type ConfigReport struct {
+	w      io.Writer
+	Errors int
+}
+
+// NewConfigReport создаёт отчёт о проверке настроек, который пишется в w
+func NewConfigReport(w io.Writer) *ConfigReport {
+	return &ConfigReport{w: w}
+}
+
+// OK параметр задан корректно
+func (r *ConfigReport) OK(name, format string, args ...any) {
+	r.line("ok", name, format, args...)
+}
+
+// Warn параметр допустим, но что-то не будет работать
+func (r *ConfigReport) Warn(name, format string, args ...any) {
+	r.line("warn", name, format, args...)
+}
+
+// Error с таким значением бот не запустится или будет работать неправильно
+func (r *ConfigReport) Error(name, format string, args ...any) {
+	r.Errors++
+	r.line("error", name, format, args...)
+}
+
+func (r *ConfigReport) line(level, name, format string, args ...any) {
+	fmt.Fprintf(r.w, "%-5s  %-26s %s\n", level, name, fmt.Sprintf(format, args...))
+}
+
+// CheckConfig проверяет настройки бота из окружения и файл настроек.
+// С online дополнительно проверяет токен запросом getMe к Telegram.
+func CheckConfig(r *ConfigReport, online bool) {
+	token := os.Getenv("TELEGRAM_BOT_TOKEN")
+	switch {
+	case token == "":
+		r.Error("TELEGRAM_BOT_TOKEN", "не задан, нужен для serve")
+	case !strings.Contains(token, ":"):
+		r.Error("TELEGRAM_BOT_TOKEN", "неверный формат, ожидается 123456:ABC...")
+	case online:
+		api, err := tgbotapi.NewBotAPI(token)
+		if err != nil {
+			r.Error("TELEGRAM_BOT_TOKEN", "Telegram отклонил токен: %v", err)
+		} else {
+			r.OK("TELEGRAM_BOT_TOKEN", "бот @%s", api.Self.UserName)
+		}
+	default:
+		r.OK("TELEGRAM_BOT_TOKEN", "задан")
+	}
+
+	if name := os.Getenv("TIMEZONE"); name == "" {
+		r.OK("TIMEZONE", "не задан, используется UTC")
+	} else if _, err := loadTimezone(name); err != nil {
+		r.Error("TIMEZONE", "неизвестный часовой пояс %q", name)
+	} else {
+		r.OK("TIMEZONE", "%s", name)
+	}
+
+	if val := os.Getenv("DEFAULT_LANG"); val != "" && normalizeLang(val) == "" {
+		r.Warn("DEFAULT_LANG", "язык %q не поддерживается, используется %s", val, defaultLang())
+	} else {
+		r.OK("DEFAULT_LANG", "%s", defaultLang())
+	}
+
+	if val := os.Getenv("DEFAULT_FUNDING_THRESHOLD"); val == "" {
+		r.OK("DEFAULT_FUNDING_THRESHOLD", "не задан, порог %.3f%%", getDefaultThreshold()*100)
+	} else if threshold, err := parseThresholdValue(val); err != nil {
+		r.Error("DEFAULT_FUNDING_THRESHOLD", "некорректное число %q", val)
+	} else if !strings.HasSuffix(val, "%") && threshold < 0.0001 {
+		// До перехода на проценты значение задавалось долей: 0.001 означало 0.1%
+		r.Warn("DEFAULT_FUNDING_THRESHOLD", "порог %.4f%% — значение задаётся в процентах, для 0.1%% укажите 0.1", threshold*100)
+	} else {
+		r.OK("DEFAULT_FUNDING_THRESHOLD", "%.3f%%", getDefaultThreshold()*100)
+	}
+
+	var badAdmins []string
+	for _, part := range strings.Split(os.Getenv("ADMIN_CHAT_IDS"), ",") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+		if _, err := strconv.ParseInt(part, 10, 64); err != nil {
+			badAdmins = append(badAdmins, part)
+		}
+	}
+	admins := getAdminIDs()
+	switch {
+	case len(badAdmins) > 0:
+		r.Error("ADMIN_CHAT_IDS", "некорректные ID: %s", strings.Join(badAdmins, ", "))
+	case len(admins) == 0:
+		r.Warn("ADMIN_CHAT_IDS", "не заданы, админские команды недоступны")
+	default:
+		r.OK("ADMIN_CHAT_IDS", "%d администраторов", len(admins))
+	}
+
+	if val := os.Getenv("PRIVATE_MODE"); val != "" {
+		if _, err := strconv.ParseBool(val); err != nil {
+			r.Error("PRIVATE_MODE", "ожидается true или false, получено %q", val)
+		} else if isPrivateMode() && len(admins) == 0 {
+			r.Warn("PRIVATE_MODE", "закрытый режим без администраторов: выдать приглашение некому")
+		} else {
+			r.OK("PRIVATE_MODE", "%t", isPrivateMode())
+		}
+	}
+
+	checkKeys(r, "OKX", "OKX_API_KEY", "OKX_SECRET_KEY", "OKX_PASSPHRASE")
+	checkKeys(r, "BingX", "BINGX_API_KEY", "BINGX_SECRET_KEY")
+
+	if val := os.Getenv("HISTORY_INTERVAL"); val != "" {
+		if interval, err := time.ParseDuration(val); err != nil || interval <= 0 {
+			r.Error("HISTORY_INTERVAL", "некорректная длительность %q", val)
+		}
+	}
+	if val := os.Getenv("HISTORY_DAYS"); val != "" {
+		if days, err := strconv.Atoi(val); err != nil || days <= 0 {
+			r.Error("HISTORY_DAYS", "ожидается положительное число дней, получено %q", val)
+		}
+	}
+	history := newRateHistory()
+	if err := checkWritableDir(history.dir); err != nil {
+		r.Error("HISTORY_DIR", "%v", err)
+	} else {
+		r.OK("HISTORY_DIR", "%s, снимок раз в %v, хранение %d дн.", history.dir, history.interval, int(history.retention.Hours()/24))
+	}
+
+	if addr := os.Getenv("WEB_ADDR"); addr == "" {
+		r.OK("WEB_ADDR", "не задан, веб-панель у бота выключена")
+	} else if _, _, err := net.SplitHostPort(addr); err != nil {
+		r.Error("WEB_ADDR", "некорректный адрес %q, ожидается host:port", addr)
+	} else {
+		r.OK("WEB_ADDR", "http://%s", addr)
+	}
+
+	checkSettingsFile(r)
+	checkNotifyTargets(r)
+}
+
+// checkKeys проверяет, что ключи биржи заданы все вместе
+func checkKeys(r *ConfigReport, exchangeName string, names ...string) {
+	var missing []string
+	for _, name := range names {
+		if os.Getenv(name) == "" {
+			missing = append(missing, name)
+		}
+	}
+	switch {
+	case len(missing) == 0:
+		r.OK(names[0], "ключи %s заданы", exchangeName)
+	case len(missing) == len(names):
+		r.Warn(names[0], "ключи %s не заданы, биржа может не работать", exchangeName)
+	default:
+		r.Error(names[0], "ключи %s заданы не полностью, нет %s", exchangeName, strings.Join(missing, ", "))
+	}
+}
+
+// checkWritableDir проверяет, что в каталог можно писать; несуществующий каталог
+// проверяется по ближайшему существующему родителю, в котором бот его создаст
+func checkWritableDir(dir string) error {
+	for {
+		info, err := os.Stat(dir)
+		if err == nil {
+			if !info.IsDir() {
+				return fmt.Errorf("%s не каталог", dir)
+			}
+			break
+		}
+		parent := filepath.Dir(dir)
+		if !os.IsNotExist(err) || parent == dir {
+			return err
+		}
+		dir = parent
+	}
+	file, err := os.CreateTemp(dir, ".check-*")
+	if err != nil {
+		return err
+	}
+	file.Close()
+	return os.Remove(file.Name())
+}
+
+// checkSettingsFile проверяет, что файл настроек читается; отсутствующий файл будет создан
+func checkSettingsFile(r *ConfigReport) {
+	data, err := os.ReadFile(settingsFile)
+	if os.IsNotExist(err) {
+		r.OK(settingsFile, "нет, будет создан в %s", filepath.Dir(mustAbs(settingsFile)))
+		return
+	}
+	if err != nil {
+		r.Error(settingsFile, "%v", err)
+		return
+	}
+	var settings Settings
+	if err := json.Unmarshal(data, &settings); err != nil {
+		r.Error(settingsFile, "повреждён: %v", err)
+		return
+	}
+	r.OK(settingsFile, "%d подписчиков, %d чатов с настройками", len(settings.Subscribers), len(settings.Chats))
+}
+
+// checkNotifyTargets проверяет цели внешних каналов; отсутствующий файл — рассылка только в Telegram
+func checkNotifyTargets(r *ConfigReport) {
+	path := notifyTargetsFile()
+	targets, err := readNotifyTargets(path)
+	if err != nil {
+		r.Error(path, "%v", err)
+		return
+	}
+	if targets == nil {
+		r.OK(path, "нет, рассылка только в Telegram")
+		return
+	}
+
+	notifiers := newExternalNotifiers()
+	counts := make(map[string]int)
+	seen := make(map[string]struct{})
+	for _, target := range targets {
+		to, err := target.recipient(notifiers)
+		if err == nil {
+			if _, dup := seen[to.Key]; dup {
+				err = fmt.Errorf("имя повторяется")
+			}
+		}
+		if err != nil {
+			r.Error(path, "цель %q: %v", target.Name, err)
+			continue
+		}
+		seen[to.Key] = struct{}{}
+		counts[target.Channel]++
+	}
+
+	var parts []string
+	for _, channel := range []string{channelDiscord, channelSlack, channelWebhook, channelEmail} {
+		if counts[channel] > 0 {
+			parts = append(parts, fmt.Sprintf("%s: %d", channel, counts[channel]))
+		}
+	}
+	if len(parts) > 0 {
+		r.OK(path, "%s", strings.Join(parts, ", "))
+	}
+
+	if counts[channelEmail] > 0 {
+		if config, err := loadSMTPConfig(); err != nil {
+			r.Error("SMTP_HOST", "%v", err)
+		} else {
+			r.OK("SMTP_HOST", "%s:%d, %s, от %s", config.Host, config.Port, config.Security, config.From)
+		}
+	}
+}
+
+// mustAbs абсолютный путь для сообщений; при ошибке возвращает путь как есть
+func mustAbs(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
@@ -0,0 +1,149 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// command описание команды бота. Краткое описание и примеры использования лежат в каталоге
+// сообщений под ключами cmd.<имя> и cmd.<имя>.usage.
+type command struct {
+	Name    string
+	Handler func(b *Bot, r *request)
+	// AdminOnly команда доступна только чатам из ADMIN_CHAT_IDS
+	AdminOnly bool
+	// MaxArgs максимальное число аргументов через пробел; anyArgs — без ограничений
+	MaxArgs int
+}
+
+// anyArgs команда принимает любое число аргументов
+const anyArgs = -1
+
+// commands реестр команд в порядке отображения в меню и /help; заполняется в init,
+// потому что /help сам обращается к реестру
+var commands []command
+
+// commandsByName индекс реестра для маршрутизации
+var commandsByName map[string]command
+
+func init() {
+	commands = []command{
+		{Name: "start", Handler: (*Bot).handleStart, MaxArgs: 1},
+		{Name: "help", Handler: (*Bot).handleHelp, MaxArgs: 1},
+		{Name: "rates", Handler: (*Bot).handleRates, MaxArgs: anyArgs},
+		{Name: "symbol", Handler: (*Bot).handleSymbol, MaxArgs: 1},
+		{Name: "top", Handler: (*Bot).handleTop, MaxArgs: anyArgs},
+		{Name: "chart", Handler: (*Bot).handleChart, MaxArgs: anyArgs},
+		{Name: "export", Handler: (*Bot).handleExport, MaxArgs: anyArgs},
+		{Name: "subscribe", Handler: (*Bot).handleSubscribe, MaxArgs: anyArgs},
+		{Name: "unsubscribe", Handler: (*Bot).handleUnsubscribe, MaxArgs: anyArgs},
+		{Name: "threshold", Handler: (*Bot).handleThreshold, MaxArgs: anyArgs},
+		{Name: "minvolume", Handler: (*Bot).handleMinVolume, MaxArgs: 1},
+		{Name: "every", Handler: (*Bot).handleEvery, MaxArgs: 1},
+		{Name: "quiet", Handler: (*Bot).handleQuiet, MaxArgs: 2},
+		{Name: "digest", Handler: (*Bot).handleDigest, MaxArgs: anyArgs},
+		{Name: "live", Handler: (*Bot).handleLive, MaxArgs: 1},
+		{Name: "tz", Handler: (*Bot).handleTimezone, MaxArgs: 1},
+		{Name: "lang", Handler: (*Bot).handleLang, MaxArgs: 1},
+
+		{Name: "status", Handler: (*Bot).handleStatus, AdminOnly: true, MaxArgs: anyArgs},
+		{Name: "broadcast", Handler: (*Bot).handleBroadcast, AdminOnly: true, MaxArgs: anyArgs},
+		{Name: "users", Handler: (*Bot).handleUsers, AdminOnly: true, MaxArgs: anyArgs},
+		{Name: "reload", Handler: (*Bot).handleReload, AdminOnly: true, MaxArgs: anyArgs},
+		{Name: "pause", Handler: (*Bot).handlePause, AdminOnly: true, MaxArgs: anyArgs},
+		{Name: "resume", Handler: (*Bot).handleResume, AdminOnly: true, MaxArgs: anyArgs},
+		{Name: "force_update", Handler: (*Bot).handleForceUpdate, AdminOnly: true, MaxArgs: anyArgs},
+		{Name: "invite", Handler: (*Bot).handleInvite, AdminOnly: true, MaxArgs: 1},
+	}
+
+	commandsByName = make(map[string]command, len(commands))
+	for _, cmd := range commands {
+		commandsByName[cmd.Name] = cmd
+	}
+}
+
+// lookupCommand ищет команду в реестре
+func lookupCommand(name string) (command, bool) {
+	cmd, ok := commandsByName[strings.ToLower(name)]
+	return cmd, ok
+}
+
+// botCommands возвращает меню команд на языке lang
+func botCommands(lang string, admin bool) []tgbotapi.BotCommand {
+	var result []tgbotapi.BotCommand
+	for _, cmd := range commands {
+		if cmd.AdminOnly && !admin {
+			continue
+		}
+		result = append(result, tgbotapi.BotCommand{
+			Command:     cmd.Name,
+			Description: tr(lang, "cmd."+cmd.Name),
+		})
+	}
+	return result
+}
+
+// registerCommands публикует меню команд в Telegram: для каждого языка и отдельно,
+// с админскими командами, для чатов администраторов
+func (b *Bot) registerCommands() {
+	configs := []tgbotapi.SetMyCommandsConfig{
+		tgbotapi.NewSetMyCommandsWithScope(tgbotapi.NewBotCommandScopeDefault(), botCommands(defaultLang(), false)...),
+	}
+	for _, lang := range supportedLangs {
+		configs = append(configs, tgbotapi.NewSetMyCommandsWithScopeAndLanguage(
+			tgbotapi.NewBotCommandScopeDefault(), lang.Code, botCommands(lang.Code, false)...))
+	}
+	for adminID := range getAdminIDs() {
+		configs = append(configs, tgbotapi.NewSetMyCommandsWithScope(
+			tgbotapi.NewBotCommandScopeChat(adminID), botCommands(defaultLang(), true)...))
+		for _, lang := range supportedLangs {
+			configs = append(configs, tgbotapi.NewSetMyCommandsWithScopeAndLanguage(
+				tgbotapi.NewBotCommandScopeChat(adminID), lang.Code, botCommands(lang.Code, true)...))
+		}
+	}
+
+	for _, config := range configs {
+		if _, err := b.bot.Request(config); err != nil {
+			log.Printf("Ошибка регистрации меню команд (%s, %q): %v", config.Scope.Type, config.LanguageCode, err)
+		}
+	}
+	log.Printf("Меню команд зарегистрировано: %d команд, %d вариантов", len(commands), len(configs))
+}
+
+// commandList форматирует список команд с краткими описаниями
+func commandList(lang string, admin bool) string {
+	var lines []string
+	for _, cmd := range botCommands(lang, admin) {
+		lines = append(lines, fmt.Sprintf("/%s - %s", cmd.Command, cmd.Description))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (b *Bot) handleStart(r *request) {
+	msg := r.Msg
+	log.Printf("Обработка команды start от пользователя %s", senderName(msg))
+	lang := msgLang(msg)
+	b.reply(msg, tr(lang, "start.text", commandList(lang, false)))
+}
+
+// handleHelp показывает список команд или подробную справку: /help threshold
+func (b *Bot) handleHelp(r *request) {
+	msg := r.Msg
+	lang := msgLang(msg)
+	name := strings.TrimPrefix(r.Arg(0), "/")
+	if name == "" {
+		b.reply(msg, tr(lang, "help.list", commandList(lang, isAdmin(msg))))
+		return
+	}
+
+	cmd, ok := lookupCommand(name)
+	if !ok || cmd.AdminOnly && !isAdmin(msg) {
+		b.reply(msg, tr(lang, "help.unknown", escapeHTML(name)))
+		return
+	}
+	b.reply(msg, fmt.Sprintf("<b>/%s</b> — %s\n\n%s", cmd.Name,
+		escapeHTML(tr(lang, "cmd."+cmd.Name)), escapeHTML(tr(lang, "cmd."+cmd.Name+".usage"))))
+}
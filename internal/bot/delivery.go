@@ -0,0 +1,189 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	retryQueueSize     = 1000
+	maxRetryAttempts   = 5
+	maxRetryAfterWaits = 3
+	retryBaseDelay     = 10 * time.Second
+)
+
+// sendErrorKind класс ошибки отправки сообщения в Telegram
+type sendErrorKind int
+
+const (
+	// sendErrorFatal сообщение не может быть доставлено (например, ошибка разметки), повтор бесполезен
+	sendErrorFatal sendErrorKind = iota
+	// sendErrorChatGone бот заблокирован, удалён из чата или чат не существует.
+	// Прочие 403 (например, нет прав писать в группе) сюда не относятся: права могут вернуть
+	sendErrorChatGone
+	// sendErrorThreadGone тема форума, выбранная для рассылки, удалена
+	sendErrorThreadGone
+	// sendErrorMigrated группа преобразована в супергруппу с новым ID
+	sendErrorMigrated
+	// sendErrorRetryAfter превышен лимит Telegram, нужно подождать retry_after
+	sendErrorRetryAfter
+	// sendErrorTransient временная ошибка сети или сервера Telegram
+	sendErrorTransient
+	// sendErrorNotModified при редактировании текст и клавиатура не изменились
+	sendErrorNotModified
+	// sendErrorMessageGone редактируемое сообщение удалено или больше не может быть изменено
+	sendErrorMessageGone
+)
+
+var (
+	// errChatGone возвращается, когда чат больше недоступен и был отписан
+	errChatGone = errors.New("чат недоступен, подписка удалена")
+	// errMessageGone возвращается, когда редактируемого сообщения больше нет
+	errMessageGone = errors.New("сообщение для редактирования не найдено")
+)
+
+// classifySendError определяет класс ошибки отправки и время ожидания для 429
+func classifySendError(err error) (sendErrorKind, time.Duration) {
+	var apiErr *tgbotapi.Error
+	if !errors.As(err, &apiErr) {
+		// Ошибки сети и неразобранные ответы (например, HTML-страница 502) считаем временными
+		return sendErrorTransient, 0
+	}
+
+	description := strings.ToLower(apiErr.Message)
+	switch {
+	case apiErr.Code == 429:
+		return sendErrorRetryAfter, time.Duration(apiErr.RetryAfter) * time.Second
+	case apiErr.Code == 403 && (strings.Contains(description, "bot was blocked by the user") ||
+		strings.Contains(description, "bot was kicked") ||
+		strings.Contains(description, "user is deactivated")):
+		return sendErrorChatGone, 0
+	case apiErr.MigrateToChatID != 0:
+		return sendErrorMigrated, 0
+	case apiErr.Code >= 500:
+		return sendErrorTransient, 0
+	case apiErr.Code == 400 && strings.Contains(description, "message is not modified"):
+		return sendErrorNotModified, 0
+	case apiErr.Code == 400 && (strings.Contains(description, "message to edit not found") ||
+		strings.Contains(description, "message can't be edited")):
+		return sendErrorMessageGone, 0
+	case apiErr.Code == 400 && strings.Contains(description, "message thread not found"):
+		return sendErrorThreadGone, 0
+	case apiErr.Code == 400 && (strings.Contains(description, "chat not found") ||
+		strings.Contains(description, "user is deactivated") ||
+		strings.Contains(description, "group chat was deactivated") ||
+		strings.Contains(description, "peer_id_invalid") ||
+		strings.Contains(description, "bot was kicked")):
+		return sendErrorChatGone, 0
+	}
+	return sendErrorFatal, 0
+}
+
+// deliver отправляет сообщения по порядку через диспетчер и обрабатывает ошибки:
+// недоступные чаты отписываются, 429 повторяются после retry_after,
+// временные ошибки переносят оставшиеся сообщения в очередь повторной отправки.
+// Возвращает ID последнего отправленного сообщения.
+func (b *Bot) deliver(msgs []*outgoingMessage) (int, error) {
+	return b.deliverAttempt(msgs, 0)
+}
+
+func (b *Bot) deliverAttempt(msgs []*outgoingMessage, attempt int) (int, error) {
+	var skippedErr error
+	lastID := 0
+	retryAfterWaits := 0
+	for i := 0; i < len(msgs); {
+		msg := msgs[i]
+		messageID, err := b.dispatcher.Send(msg)
+		if err == nil {
+			lastID = messageID
+			i++
+			continue
+		}
+
+		kind, wait := classifySendError(err)
+		switch kind {
+		case sendErrorNotModified:
+			// Текст при редактировании не изменился — это не ошибка
+			lastID = msg.EditMessageID
+			i++
+		case sendErrorRetryAfter:
+			retryAfterWaits++
+			if retryAfterWaits > maxRetryAfterWaits {
+				if msg.NoRetry {
+					return lastID, fmt.Errorf("превышен лимит Telegram: %v", err)
+				}
+				b.enqueueRetry(msgs[i:], attempt, wait)
+				return lastID, fmt.Errorf("превышен лимит Telegram, сообщение %d/%d отложено: %v", i+1, len(msgs), err)
+			}
+			// Диспетчер сам не пустит сообщения в этот чат до истечения retry_after
+			log.Printf("Лимит Telegram для чата %d, повтор через %v: %v", msg.Target.ChatID, wait, err)
+		case sendErrorMessageGone:
+			return lastID, errMessageGone
+		case sendErrorChatGone:
+			log.Printf("Чат %d недоступен (%v), удаляю подписку", msg.Target.ChatID, err)
+			dropDelivery(msg.Target.ChatID)
+			return lastID, errChatGone
+		case sendErrorMigrated:
+			var apiErr *tgbotapi.Error
+			errors.As(err, &apiErr)
+			migrateChat(msg.Target.ChatID, apiErr.MigrateToChatID)
+			for _, rest := range msgs[i:] {
+				rest.Target.ChatID = apiErr.MigrateToChatID
+			}
+		case sendErrorThreadGone:
+			log.Printf("Тема %d в чате %d удалена, рассылка переключена в основной чат", msg.Target.ThreadID, msg.Target.ChatID)
+			updateChatSettings(msg.Target.ChatID, func(settings *ChatSettings) {
+				settings.ThreadID = 0
+			})
+			for _, rest := range msgs[i:] {
+				rest.Target.ThreadID = 0
+			}
+		case sendErrorTransient:
+			if msg.NoRetry {
+				return lastID, err
+			}
+			b.enqueueRetry(msgs[i:], attempt, 0)
+			return lastID, fmt.Errorf("временная ошибка, сообщение %d/%d поставлено в очередь повтора: %v", i+1, len(msgs), err)
+		default:
+			// Повтор не поможет, пропускаем сообщение и отправляем остальные
+			log.Printf("Ошибка отправки сообщения %d/%d в чат %d: %v", i+1, len(msgs), msg.Target.ChatID, err)
+			skippedErr = err
+			i++
+		}
+	}
+	return lastID, skippedErr
+}
+
+// enqueueRetry откладывает оставшиеся сообщения для повторной отправки
+// с экспоненциальной задержкой
+func (b *Bot) enqueueRetry(msgs []*outgoingMessage, attempt int, minDelay time.Duration) {
+	chatID := msgs[0].Target.ChatID
+	if attempt >= maxRetryAttempts {
+		log.Printf("Сообщение в чат %d не доставлено после %d попыток, отбрасываю", chatID, attempt)
+		return
+	}
+	if b.retryPending.Add(1) > retryQueueSize {
+		b.retryPending.Add(-1)
+		log.Printf("Очередь повтора заполнена, сообщение в чат %d отброшено", chatID)
+		return
+	}
+
+	delay := retryBaseDelay << attempt
+	if delay < minDelay {
+		delay = minDelay
+	}
+	log.Printf("Сообщение в чат %d поставлено в очередь повтора (попытка %d через %v)",
+		chatID, attempt+1, delay)
+
+	time.AfterFunc(delay, func() {
+		b.retryPending.Add(-1)
+		if _, err := b.deliverAttempt(msgs, attempt+1); err != nil {
+			log.Printf("Повторная отправка в чат %d: %v", chatID, err)
+		}
+	})
+}
@@ -0,0 +1,323 @@
+package bot
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Лимиты Telegram: ~30 сообщений в секунду на бота, 1 сообщение в секунду
+// в личный чат и 20 сообщений в минуту в группу
+const (
+	globalSendRate     = 30.0
+	privateChatRate    = 1.0
+	groupChatRate      = 20.0 / 60.0
+	maxConcurrentSends = 10
+	chatBucketIdleTTL  = time.Minute
+)
+
+// sendPriority приоритет исходящего сообщения; меньшее значение отправляется раньше
+type sendPriority int
+
+const (
+	// priorityReply ответы на команды пользователей
+	priorityReply sendPriority = iota
+	// priorityBroadcast плановые рассылки и оповещения
+	priorityBroadcast
+
+	priorityCount
+)
+
+// tokenBucket ограничитель скорости «ведро токенов»
+type tokenBucket struct {
+	rate         float64
+	burst        float64
+	tokens       float64
+	last         time.Time
+	blockedUntil time.Time
+}
+
+func newTokenBucket(rate, burst float64) *tokenBucket {
+	return &tokenBucket{rate: rate, burst: burst, tokens: burst, last: time.Now()}
+}
+
+// wait возвращает, сколько ждать до появления токена (0 — токен есть)
+func (tb *tokenBucket) wait(now time.Time) time.Duration {
+	if now.Before(tb.blockedUntil) {
+		return tb.blockedUntil.Sub(now)
+	}
+	tb.tokens += now.Sub(tb.last).Seconds() * tb.rate
+	if tb.tokens > tb.burst {
+		tb.tokens = tb.burst
+	}
+	tb.last = now
+	if tb.tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - tb.tokens) / tb.rate * float64(time.Second))
+}
+
+func (tb *tokenBucket) take() {
+	tb.tokens--
+}
+
+// outgoingMessage одно сообщение в очереди отправки
+type outgoingMessage struct {
+	Target chatTarget
+	Text   string
+	Markup *tgbotapi.InlineKeyboardMarkup
+	// EditMessageID если задан, вместо отправки нового сообщения редактируется существующее
+	EditMessageID int
+	// File если задан, отправляется файл, а Text становится подписью к нему
+	File     *attachment
+	Priority sendPriority
+	// NoRetry при временной ошибке не ставить в очередь повтора, а вернуть ошибку
+	NoRetry bool
+
+	queuedAt time.Time
+	done     chan sendResult
+}
+
+// attachment файл для отправки: изображение (sendPhoto) или документ (sendDocument)
+type attachment struct {
+	Method string
+	Field  string
+	Data   tgbotapi.FileBytes
+}
+
+// photoAttachment изображение, которое Telegram покажет в чате
+func photoAttachment(name string, data []byte) *attachment {
+	return &attachment{Method: "sendPhoto", Field: "photo", Data: tgbotapi.FileBytes{Name: name, Bytes: data}}
+}
+
+// documentAttachment файл, который отправляется как документ
+func documentAttachment(name string, data []byte) *attachment {
+	return &attachment{Method: "sendDocument", Field: "document", Data: tgbotapi.FileBytes{Name: name, Bytes: data}}
+}
+
+// sendResult результат отправки: ID отправленного сообщения или ошибка
+type sendResult struct {
+	MessageID int
+	Err       error
+}
+
+// dispatcherStats метрики очереди отправки
+type dispatcherStats struct {
+	Queued      [priorityCount]int
+	InFlight    int
+	Sent        int64
+	Failed      int64
+	RateLimited int64
+	AvgWait     time.Duration
+	MaxWait     time.Duration
+}
+
+// dispatcher единая очередь исходящих сообщений Telegram с приоритетами
+// и ограничением скорости: общим на бота и отдельным на каждый чат
+type dispatcher struct {
+	send func(msg *outgoingMessage) (int, error)
+
+	mu        sync.Mutex
+	queues    [priorityCount][]*outgoingMessage
+	global    *tokenBucket
+	chats     map[int64]*tokenBucket
+	inFlight  map[int64]struct{}
+	lastPrune time.Time
+
+	sent        int64
+	failed      int64
+	rateLimited int64
+	waitTotal   time.Duration
+	maxWait     time.Duration
+
+	wake  chan struct{}
+	slots chan struct{}
+}
+
+func newDispatcher(send func(msg *outgoingMessage) (int, error)) *dispatcher {
+	return &dispatcher{
+		send:     send,
+		global:   newTokenBucket(globalSendRate, globalSendRate),
+		chats:    make(map[int64]*tokenBucket),
+		inFlight: make(map[int64]struct{}),
+		wake:     make(chan struct{}, 1),
+		slots:    make(chan struct{}, maxConcurrentSends),
+	}
+}
+
+// Send ставит сообщение в очередь и ждёт результата отправки
+func (d *dispatcher) Send(msg *outgoingMessage) (int, error) {
+	msg.queuedAt = time.Now()
+	msg.done = make(chan sendResult, 1)
+
+	d.mu.Lock()
+	d.queues[msg.Priority] = append(d.queues[msg.Priority], msg)
+	d.mu.Unlock()
+	d.notify()
+
+	result := <-msg.done
+	return result.MessageID, result.Err
+}
+
+// Stats возвращает текущие метрики очереди
+func (d *dispatcher) Stats() dispatcherStats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	stats := dispatcherStats{
+		InFlight:    len(d.inFlight),
+		Sent:        d.sent,
+		Failed:      d.failed,
+		RateLimited: d.rateLimited,
+		MaxWait:     d.maxWait,
+	}
+	for p := range d.queues {
+		stats.Queued[p] = len(d.queues[p])
+	}
+	if processed := d.sent + d.failed; processed > 0 {
+		stats.AvgWait = d.waitTotal / time.Duration(processed)
+	}
+	return stats
+}
+
+func (d *dispatcher) notify() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run выбирает сообщения из очереди с учётом лимитов и отправляет их
+func (d *dispatcher) run() {
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+
+	for {
+		msg, wait := d.next()
+		if msg != nil {
+			d.slots <- struct{}{}
+			go d.process(msg)
+			continue
+		}
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(wait)
+		select {
+		case <-d.wake:
+		case <-timer.C:
+		}
+	}
+}
+
+// next возвращает первое сообщение с наивысшим приоритетом, которое можно
+// отправить прямо сейчас, или время ожидания до ближайшей возможности.
+// Сообщения одного чата отправляются строго по порядку.
+func (d *dispatcher) next() (*outgoingMessage, time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	d.pruneChatBuckets(now)
+
+	if wait := d.global.wait(now); wait > 0 {
+		return nil, wait
+	}
+
+	minWait := time.Hour
+	blocked := make(map[int64]struct{})
+	for p := range d.queues {
+		for i, msg := range d.queues[p] {
+			chatID := msg.Target.ChatID
+			if _, ok := blocked[chatID]; ok {
+				continue
+			}
+			if _, ok := d.inFlight[chatID]; ok {
+				blocked[chatID] = struct{}{}
+				continue
+			}
+
+			bucket := d.chatBucket(chatID)
+			if wait := bucket.wait(now); wait > 0 {
+				blocked[chatID] = struct{}{}
+				if wait < minWait {
+					minWait = wait
+				}
+				continue
+			}
+
+			bucket.take()
+			d.global.take()
+			d.inFlight[chatID] = struct{}{}
+			d.queues[p] = append(d.queues[p][:i], d.queues[p][i+1:]...)
+			return msg, 0
+		}
+	}
+	return nil, minWait
+}
+
+// chatBucket возвращает ограничитель скорости для чата; у групп лимит строже
+func (d *dispatcher) chatBucket(chatID int64) *tokenBucket {
+	bucket, ok := d.chats[chatID]
+	if !ok {
+		rate := privateChatRate
+		if chatID < 0 {
+			rate = groupChatRate
+		}
+		bucket = newTokenBucket(rate, 1)
+		d.chats[chatID] = bucket
+	}
+	return bucket
+}
+
+// pruneChatBuckets удаляет ограничители чатов, в которые давно ничего не отправлялось
+func (d *dispatcher) pruneChatBuckets(now time.Time) {
+	if now.Sub(d.lastPrune) < chatBucketIdleTTL {
+		return
+	}
+	d.lastPrune = now
+	for chatID, bucket := range d.chats {
+		if _, ok := d.inFlight[chatID]; ok {
+			continue
+		}
+		if now.Sub(bucket.last) > chatBucketIdleTTL && now.After(bucket.blockedUntil) {
+			delete(d.chats, chatID)
+		}
+	}
+}
+
+func (d *dispatcher) process(msg *outgoingMessage) {
+	defer func() { <-d.slots }()
+
+	messageID, err := d.send(msg)
+
+	d.mu.Lock()
+	delete(d.inFlight, msg.Target.ChatID)
+	waited := time.Since(msg.queuedAt)
+	d.waitTotal += waited
+	if waited > d.maxWait {
+		d.maxWait = waited
+	}
+	if err == nil {
+		d.sent++
+	} else {
+		d.failed++
+		var apiErr *tgbotapi.Error
+		if errors.As(err, &apiErr) && apiErr.Code == 429 {
+			// Не отправляем в этот чат ничего, пока не истечёт retry_after
+			d.rateLimited++
+			d.chatBucket(msg.Target.ChatID).blockedUntil =
+				time.Now().Add(time.Duration(apiErr.RetryAfter) * time.Second)
+		}
+	}
+	d.mu.Unlock()
+
+	d.notify()
+	msg.done <- sendResult{MessageID: messageID, Err: err}
+}
@@ -0,0 +1,292 @@
+package bot
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"html/template"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// smtpTimeout на всю отправку одного письма
+	smtpTimeout = 30 * time.Second
+	// emailRowLimit сколько ставок биржи показывать в письме
+	emailRowLimit = 50
+)
+
+// Режимы шифрования SMTP_SECURITY
+const (
+	smtpTLS      = "tls"
+	smtpStartTLS = "starttls"
+	smtpNone     = "none"
+)
+
+// smtpConfig параметры SMTP-сервера из переменных окружения
+type smtpConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	Security string
+}
+
+// loadSMTPConfig читает SMTP_*; по умолчанию порт 587 со STARTTLS, для порта 465 — TLS
+func loadSMTPConfig() (smtpConfig, error) {
+	config := smtpConfig{
+		Host:     os.Getenv("SMTP_HOST"),
+		Port:     587,
+		Username: os.Getenv("SMTP_USER"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     os.Getenv("SMTP_FROM"),
+		Security: strings.ToLower(os.Getenv("SMTP_SECURITY")),
+	}
+	if config.Host == "" {
+		return config, fmt.Errorf("не задан SMTP_HOST")
+	}
+	if val := os.Getenv("SMTP_PORT"); val != "" {
+		port, err := strconv.Atoi(val)
+		if err != nil || port <= 0 || port > 65535 {
+			return config, fmt.Errorf("некорректный SMTP_PORT %q", val)
+		}
+		config.Port = port
+	}
+	switch config.Security {
+	case "":
+		config.Security = smtpStartTLS
+		if config.Port == 465 {
+			config.Security = smtpTLS
+		}
+	case smtpTLS, smtpStartTLS, smtpNone:
+	default:
+		return config, fmt.Errorf("некорректный SMTP_SECURITY %q, ожидается tls, starttls или none", config.Security)
+	}
+	if config.From == "" {
+		config.From = config.Username
+	}
+	if _, err := mail.ParseAddress(config.From); err != nil {
+		return config, fmt.Errorf("некорректный SMTP_FROM %q", config.From)
+	}
+	return config, nil
+}
+
+// send отправляет готовое письмо; без шифрования net/smtp передаёт пароль только на localhost
+func (c smtpConfig) send(recipients []string, message []byte) error {
+	addr := net.JoinHostPort(c.Host, strconv.Itoa(c.Port))
+	conn, err := net.DialTimeout("tcp", addr, smtpTimeout)
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(smtpTimeout))
+	if c.Security == smtpTLS {
+		conn = tls.Client(conn, &tls.Config{ServerName: c.Host})
+	}
+
+	client, err := smtp.NewClient(conn, c.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if c.Security == smtpStartTLS {
+		if err := client.StartTLS(&tls.Config{ServerName: c.Host}); err != nil {
+			return fmt.Errorf("STARTTLS: %v", err)
+		}
+	}
+	if c.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", c.Username, c.Password, c.Host)); err != nil {
+			return err
+		}
+	}
+
+	from, _ := mail.ParseAddress(c.From)
+	if err := client.Mail(from.Address); err != nil {
+		return err
+	}
+	for _, to := range recipients {
+		if err := client.Rcpt(to); err != nil {
+			return fmt.Errorf("%s: %v", to, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(message); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// buildEmail собирает письмо multipart/alternative: текст для почтовиков без HTML и HTML-версия
+func buildEmail(from string, to []string, subject, text, htmlBody string, now time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+	body := multipart.NewWriter(&buf)
+
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", body.Boundary())
+
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", text},
+		{"text/html; charset=utf-8", htmlBody},
+	} {
+		w, err := body.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := body.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// emailNotifier доставка по SMTP; настройки SMTP читаются при каждой отправке, чтобы работал /reload.
+// Как и в других внешних каналах, письмо без ставок выше порога не отправляется.
+type emailNotifier struct{}
+
+func (emailNotifier) Channel() string { return channelEmail }
+
+func (n emailNotifier) NotifyRates(to recipient, alert *ratesAlert) error {
+	report := alert.report(emailRowLimit)
+	if report.Empty() {
+		return nil
+	}
+	var htmlBody strings.Builder
+	if err := emailTemplate.Execute(&htmlBody, newEmailView(report)); err != nil {
+		return err
+	}
+	subject := reportTitle(report) + " · " + report.Time.In(report.Loc).Format("02.01 15:04 MST")
+	return n.send(to, subject, renderPlain(report), htmlBody.String(), report.Time)
+}
+
+// NotifyText отправляет объявление; тема — первая строка без разметки
+func (n emailNotifier) NotifyText(to recipient, text string) error {
+	plain := plainText(text)
+	subject, _, _ := strings.Cut(plain, "\n")
+	htmlBody := "<html><body><p>" + strings.ReplaceAll(text, "\n", "<br>\n") + "</p></body></html>"
+	return n.send(to, subject, plain, htmlBody, time.Now())
+}
+
+func (emailNotifier) send(to recipient, subject, text, htmlBody string, now time.Time) error {
+	config, err := loadSMTPConfig()
+	if err != nil {
+		return err
+	}
+	message, err := buildEmail(config.From, to.Emails, subject, text, htmlBody, now)
+	if err != nil {
+		return err
+	}
+	return config.send(to.Emails, message)
+}
+
+// emailView данные HTML-версии письма; текстовая версия — renderPlain
+type emailView struct {
+	Lang     string
+	Title    string
+	Footer   string
+	Columns  []string
+	Sections []emailSection
+}
+
+type emailSection struct {
+	Exchange string
+	Rows     []emailRow
+	More     string
+}
+
+type emailRow struct {
+	Symbol   string
+	Rate     string
+	Rate8h   string
+	Positive bool
+	Volume   string
+	Payment  string
+}
+
+func newEmailView(r ratesReport) emailView {
+	lang := r.Lang
+	view := emailView{
+		Lang:   lang,
+		Title:  reportTitle(r),
+		Footer: reportFooter(r),
+		Columns: []string{
+			tr(lang, "col.symbol"), tr(lang, "col.rate"), tr(lang, "col.rate8h"), tr(lang, "col.volume"), tr(lang, "col.payment"),
+		},
+	}
+	for _, section := range r.Sections {
+		s := emailSection{Exchange: section.Exchange}
+		if section.Hidden > 0 {
+			s.More = trn(lang, "notify.more", section.Hidden)
+		}
+		for _, rate := range section.Rates {
+			payment := tr(lang, "rates.unknown_time")
+			if t, ok := nextFundingTime(rate); ok {
+				payment = formatFundingTime(t, r.Loc, r.Time, lang)
+			}
+			s.Rows = append(s.Rows, emailRow{
+				Symbol:   rate.Symbol,
+				Rate:     fmt.Sprintf("%+.4f%%", rate.Rate*100),
+				Rate8h:   fmt.Sprintf("%+.4f%%", normalizedRate8h(section.Exchange, rate.Rate)*100),
+				Positive: rate.Rate > 0,
+				Volume:   formatVolume(rate),
+				Payment:  payment,
+			})
+		}
+		view.Sections = append(view.Sections, s)
+	}
+	return view
+}
+
+// emailTemplate HTML-версия письма; стили встроены, потому что почтовики не поддерживают <style>
+var emailTemplate = template.Must(template.New("email").Parse(`<!doctype html>
+<html lang="{{.Lang}}">
+<body style="margin:0;padding:16px;font-family:Arial,Helvetica,sans-serif;font-size:14px;color:#222">
+<h2 style="margin:0 0 12px">{{.Title}}</h2>
+{{range .Sections}}
+<h3 style="margin:16px 0 6px">📈 {{.Exchange}}</h3>
+<table cellpadding="4" cellspacing="0" style="border-collapse:collapse">
+<tr style="background:#f5f5f5">{{range $.Columns}}<th align="left" style="border-bottom:1px solid #e4e4e4">{{.}}</th>{{end}}</tr>
+{{range .Rows}}<tr>
+<td style="border-bottom:1px solid #e4e4e4">{{.Symbol}}</td>
+<td align="right" style="border-bottom:1px solid #e4e4e4;color:{{if .Positive}}#1a8f3c{{else}}#c62828{{end}}">{{.Rate}}</td>
+<td align="right" style="border-bottom:1px solid #e4e4e4">{{.Rate8h}}</td>
+<td align="right" style="border-bottom:1px solid #e4e4e4">{{.Volume}}</td>
+<td style="border-bottom:1px solid #e4e4e4">{{.Payment}}</td>
+</tr>
+{{end}}</table>
+{{with .More}}<p style="margin:4px 0;color:#777"><i>{{.}}</i></p>{{end}}
+{{end}}
+<p style="margin-top:16px;color:#777;font-size:12px">{{.Footer}}</p>
+</body>
+</html>
+`))
@@ -0,0 +1,217 @@
+package bot
+
+import (
+	"bufio"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	exchanges "github.com/petrixs/cr-exchanges"
+)
+
+// fakeSMTP SMTP-сервер для тестов: принимает любые письма и запоминает команды и данные
+type fakeSMTP struct {
+	listener net.Listener
+	wg       sync.WaitGroup
+
+	mu       sync.Mutex
+	commands []string
+	data     []string
+}
+
+func startFakeSMTP(t *testing.T) *fakeSMTP {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("не удалось открыть порт: %v", err)
+	}
+	s := &fakeSMTP{listener: listener}
+	s.wg.Add(1)
+	go s.serve()
+	t.Cleanup(func() {
+		listener.Close()
+		s.wg.Wait()
+	})
+
+	t.Setenv("SMTP_HOST", "127.0.0.1")
+	t.Setenv("SMTP_PORT", strconv.Itoa(listener.Addr().(*net.TCPAddr).Port))
+	t.Setenv("SMTP_SECURITY", smtpNone)
+	t.Setenv("SMTP_FROM", "Screener <bot@example.com>")
+	t.Setenv("SMTP_USER", "")
+	t.Setenv("SMTP_PASSWORD", "")
+	return s
+}
+
+func (s *fakeSMTP) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.handle(conn)
+		}()
+	}
+}
+
+func (s *fakeSMTP) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+
+	reply("220 localhost ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		s.mu.Lock()
+		s.commands = append(s.commands, line)
+		s.mu.Unlock()
+
+		verb := strings.ToUpper(strings.Fields(line + " ")[0])
+		switch verb {
+		case "EHLO", "HELO":
+			reply("250 localhost")
+		case "DATA":
+			reply("354 end with <CRLF>.<CRLF>")
+			var data strings.Builder
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(line, "."))
+			}
+			s.mu.Lock()
+			s.data = append(s.data, data.String())
+			s.mu.Unlock()
+			reply("250 OK")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+// received команды и письма, полученные сервером
+func (s *fakeSMTP) received() ([]string, []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.commands...), append([]string(nil), s.data...)
+}
+
+func testEmailRecipient(emails ...string) recipient {
+	return recipient{
+		Key:        channelEmail + ":test",
+		Notifier:   emailNotifier{},
+		Emails:     emails,
+		Thresholds: rateThresholds{Positive: 0.001, Negative: 0.001},
+		Lang:       langEN,
+		Loc:        time.UTC,
+	}
+}
+
+func testRatesAlert(to recipient, rates map[string][]exchanges.FundingRate) *ratesAlert {
+	return &ratesAlert{Rates: rates, Prices: buildPriceIndex(rates), Options: to.reportOptions(time.Now())}
+}
+
+func TestEmailNotifyRates(t *testing.T) {
+	server := startFakeSMTP(t)
+	to := testEmailRecipient("ann@example.com", "risk@example.com")
+	alert := testRatesAlert(to, map[string][]exchanges.FundingRate{
+		"Binance": {
+			{Symbol: "BTCUSDT", Rate: 0.002, VolumeUSDT24h: 1e9},
+			{Symbol: "ETHUSDT", Rate: -0.0015, VolumeUSDT24h: 5e8},
+		},
+	})
+
+	if err := to.Notifier.NotifyRates(to, alert); err != nil {
+		t.Fatalf("NotifyRates: %v", err)
+	}
+
+	commands, data := server.received()
+	want := []string{
+		"MAIL FROM:<bot@example.com>",
+		"RCPT TO:<ann@example.com>",
+		"RCPT TO:<risk@example.com>",
+	}
+	for _, command := range want {
+		found := false
+		for _, got := range commands {
+			if strings.HasPrefix(got, command) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("сервер не получил %q, команды: %q", command, commands)
+		}
+	}
+	if len(data) != 1 {
+		t.Fatalf("ожидалось одно письмо, получено %d", len(data))
+	}
+
+	message, err := mail.ReadMessage(strings.NewReader(data[0]))
+	if err != nil {
+		t.Fatalf("письмо не разбирается: %v", err)
+	}
+	mediaType, params, err := mime.ParseMediaType(message.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type = %q, ожидается multipart/alternative", message.Header.Get("Content-Type"))
+	}
+
+	parts := make(map[string]string)
+	reader := multipart.NewReader(message.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("ошибка чтения части письма: %v", err)
+		}
+		body, err := io.ReadAll(part)
+		if err != nil {
+			t.Fatalf("ошибка чтения части письма: %v", err)
+		}
+		contentType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		parts[contentType] = string(body)
+	}
+	for _, contentType := range []string{"text/plain", "text/html"} {
+		if !strings.Contains(parts[contentType], "BTCUSDT") {
+			t.Errorf("в части %s нет ставки BTCUSDT: %q", contentType, parts[contentType])
+		}
+	}
+}
+
+func TestEmailNotifyRatesNothingAboveThreshold(t *testing.T) {
+	server := startFakeSMTP(t)
+	to := testEmailRecipient("ann@example.com")
+	alert := testRatesAlert(to, map[string][]exchanges.FundingRate{
+		"Binance": {{Symbol: "BTCUSDT", Rate: 0.0001, VolumeUSDT24h: 1e9}},
+	})
+
+	if err := to.Notifier.NotifyRates(to, alert); err != nil {
+		t.Fatalf("NotifyRates: %v", err)
+	}
+	if commands, _ := server.received(); len(commands) != 0 {
+		t.Errorf("письмо без ставок выше порога не должно отправляться, команды: %q", commands)
+	}
+}
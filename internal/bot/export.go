@@ -0,0 +1,260 @@
+package bot
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	exchanges "github.com/petrixs/cr-exchanges"
+)
+
+// Форматы выгрузки
+const (
+	exportCSV  = "csv"
+	exportJSON = "json"
+)
+
+// maxExportSize Telegram не принимает от ботов документы больше 50 МБ
+const maxExportSize = 50 << 20
+
+// exportRequest параметры выгрузки: без периода выгружаются текущие ставки из кэша, с периодом — история
+type exportRequest struct {
+	Format string
+	Filter rateFilter
+	Period time.Duration
+}
+
+// exportRow строка выгрузки; объёмы есть только у текущих ставок
+type exportRow struct {
+	Time          time.Time  `json:"time"`
+	Exchange      string     `json:"exchange"`
+	Symbol        string     `json:"symbol"`
+	Base          string     `json:"base"`
+	Rate          float64    `json:"rate"`
+	Rate8h        float64    `json:"rate_8h"`
+	NextFunding   *time.Time `json:"next_funding,omitempty"`
+	Volume24h     float64    `json:"volume_24h,omitempty"`
+	VolumeUSDT24h float64    `json:"volume_usdt_24h,omitempty"`
+}
+
+// exportHeader столбцы CSV в порядке полей exportRow
+var exportHeader = []string{"time", "exchange", "symbol", "base", "rate", "rate_8h", "next_funding", "volume_24h", "volume_usdt_24h"}
+
+// parseExportArgs разбирает аргументы: csv|json [биржи] [период]
+func parseExportArgs(args []string) (exportRequest, error) {
+	var req exportRequest
+	if len(args) > 0 {
+		req.Format = strings.ToLower(args[0])
+	}
+	if req.Format != exportCSV && req.Format != exportJSON {
+		return req, errorf("err.bad_format")
+	}
+	for _, arg := range args[1:] {
+		if period, err := parsePeriod(arg); err == nil {
+			req.Period = period
+			continue
+		}
+		if err := req.Filter.addExchanges(arg); err != nil {
+			return req, err
+		}
+	}
+	return req, nil
+}
+
+// currentExportRows строки выгрузки из текущих ставок
+func currentExportRows(rates map[string][]exchanges.FundingRate, filter rateFilter, now time.Time) []exportRow {
+	now = now.Truncate(time.Second)
+	var rows []exportRow
+	for exchangeName, exchangeRates := range rates {
+		if !filter.matchExchange(exchangeName) {
+			continue
+		}
+		for _, rate := range exchangeRates {
+			rows = append(rows, newExportRow(exchangeName, rate, now))
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Exchange != rows[j].Exchange {
+			return rows[i].Exchange < rows[j].Exchange
+		}
+		return rows[i].Symbol < rows[j].Symbol
+	})
+	return rows
+}
+
+// newExportRow строка выгрузки для текущей ставки биржи
+func newExportRow(exchangeName string, rate exchanges.FundingRate, now time.Time) exportRow {
+	row := exportRow{
+		Time:          now,
+		Exchange:      exchangeName,
+		Symbol:        rate.Symbol,
+		Base:          normalizeSymbol(rate.Symbol),
+		Rate:          rate.Rate,
+		Rate8h:        normalizedRate8h(exchangeName, rate.Rate),
+		Volume24h:     rate.Volume24h,
+		VolumeUSDT24h: rate.VolumeUSDT24h,
+	}
+	if t, ok := nextFundingTime(rate); ok {
+		row.NextFunding = &t
+	}
+	return row
+}
+
+// historyExportRows строки выгрузки из истории; снимки уже упорядочены по времени
+func historyExportRows(points []historyPoint) []exportRow {
+	bases := make(map[string]string)
+	rows := make([]exportRow, 0, len(points))
+	for _, p := range points {
+		base, ok := bases[p.Symbol]
+		if !ok {
+			base = normalizeSymbol(p.Symbol)
+			bases[p.Symbol] = base
+		}
+		row := exportRow{
+			Time:     p.Time,
+			Exchange: p.Exchange,
+			Symbol:   p.Symbol,
+			Base:     base,
+			Rate:     p.Rate,
+			Rate8h:   normalizedRate8h(p.Exchange, p.Rate),
+		}
+		if !p.NextFunding.IsZero() {
+			next := p.NextFunding
+			row.NextFunding = &next
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// exportRows собирает строки выгрузки: текущие ставки из rates или историю за период
+func exportRows(req exportRequest, rates map[string][]exchanges.FundingRate, history *rateHistory, now time.Time) ([]exportRow, error) {
+	if req.Period == 0 {
+		return currentExportRows(rates, req.Filter, now), nil
+	}
+	points, err := history.query(now.Add(-req.Period), now, func(exchangeName, _ string) bool {
+		return req.Filter.matchExchange(exchangeName)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return historyExportRows(points), nil
+}
+
+// writeExport записывает строки в CSV или JSON; время — в UTC в формате RFC 3339
+func writeExport(w io.Writer, format string, rows []exportRow) error {
+	if format == exportJSON {
+		for i := range rows {
+			rows[i].Time = rows[i].Time.UTC()
+			if rows[i].NextFunding != nil {
+				next := rows[i].NextFunding.UTC()
+				rows[i].NextFunding = &next
+			}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(rows)
+	}
+
+	cw := csv.NewWriter(w)
+	cw.Write(exportHeader)
+	for _, row := range rows {
+		nextFunding := ""
+		if row.NextFunding != nil {
+			nextFunding = row.NextFunding.UTC().Format(time.RFC3339)
+		}
+		cw.Write([]string{
+			row.Time.UTC().Format(time.RFC3339),
+			row.Exchange,
+			row.Symbol,
+			row.Base,
+			strconv.FormatFloat(row.Rate, 'g', -1, 64),
+			strconv.FormatFloat(row.Rate8h, 'g', -1, 64),
+			nextFunding,
+			formatExportVolume(row.Volume24h),
+			formatExportVolume(row.VolumeUSDT24h),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// formatExportVolume объём для CSV; неизвестный объём — пустая ячейка
+func formatExportVolume(volume float64) string {
+	if volume == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(volume, 'f', -1, 64)
+}
+
+// exportFileName имя файла выгрузки: funding_binance_7d_20261018-1530.csv
+func exportFileName(req exportRequest, args []string, now time.Time) string {
+	exchangesPart := "all"
+	if len(req.Filter.Exchanges) > 0 {
+		names := make([]string, 0, len(req.Filter.Exchanges))
+		for name := range req.Filter.Exchanges {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		exchangesPart = strings.Join(names, "-")
+	}
+	periodPart := "current"
+	for _, arg := range args[1:] {
+		if _, err := parsePeriod(arg); err == nil {
+			periodPart = strings.ToLower(arg)
+		}
+	}
+	return fmt.Sprintf("funding_%s_%s_%s.%s", exchangesPart, periodPart, now.UTC().Format("20060102-1504"), req.Format)
+}
+
+// handleExport отправляет документ со ставками: /export csv, /export json binance 7d
+func (b *Bot) handleExport(r *request) {
+	msg := r.Msg
+	lang := msgLang(msg)
+	req, err := parseExportArgs(r.Args)
+	if err != nil {
+		b.reply(msg, tr(lang, "error", escapeHTML(localize(lang, err)))+"\n\n"+escapeHTML(tr(lang, "cmd.export.usage")))
+		return
+	}
+	if err := req.Filter.validateExchanges(b.exchanges); err != nil {
+		b.reply(msg, tr(lang, "error", escapeHTML(localize(lang, err))))
+		return
+	}
+	if req.Period > b.history.retention {
+		b.reply(msg, tr(lang, "history.too_long", formatDuration(b.history.retention, lang)))
+		return
+	}
+
+	now := time.Now()
+	rows, err := exportRows(req, b.cache.GetAllRates(), b.history, now)
+	if err != nil {
+		log.Printf("Ошибка чтения истории для /export: %v", err)
+		b.reply(msg, tr(lang, "export.error"))
+		return
+	}
+	if len(rows) == 0 {
+		b.reply(msg, tr(lang, "export.empty"))
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := writeExport(&buf, req.Format, rows); err != nil {
+		log.Printf("Ошибка формирования выгрузки: %v", err)
+		b.reply(msg, tr(lang, "export.error"))
+		return
+	}
+	if buf.Len() > maxExportSize {
+		b.reply(msg, tr(lang, "export.too_large", buf.Len()>>20))
+		return
+	}
+
+	log.Printf("Выгрузка %s: %d строк, %d байт в чат %d", req.Format, len(rows), buf.Len(), msg.Chat.ID)
+	b.replyFile(msg, documentAttachment(exportFileName(req, r.Args, now), buf.Bytes()), trn(lang, "export.caption", len(rows)))
+}
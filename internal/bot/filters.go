@@ -0,0 +1,189 @@
+package bot
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	exchanges "github.com/petrixs/cr-exchanges"
+)
+
+// Направления ставок в фильтре
+const (
+	directionAny      = ""
+	directionPositive = "positive"
+	directionNegative = "negative"
+)
+
+// rateFilter параметры отбора ставок из аргументов команды.
+// Разбирается parseRateFilter и используется всеми командами, принимающими фильтры.
+type rateFilter struct {
+	Limit     int
+	Direction string
+	MinVolume float64
+	// Exchanges названия бирж в нижнем регистре; пусто — все биржи
+	Exchanges map[string]struct{}
+}
+
+// parseRateFilter разбирает аргументы вида: 20 negative minvol=5M exchange=binance,bybit
+func parseRateFilter(args []string) (rateFilter, error) {
+	var filter rateFilter
+	for _, token := range args {
+		if key, value, ok := strings.Cut(token, "="); ok {
+			switch strings.ToLower(key) {
+			case "minvol", "minvolume", "vol":
+				amount, err := parseAmount(value)
+				if err != nil {
+					return filter, errorf("err.bad_volume", value)
+				}
+				filter.MinVolume = amount
+			case "exchange", "exchanges", "ex":
+				if filter.Exchanges == nil {
+					filter.Exchanges = make(map[string]struct{})
+				}
+				for _, name := range strings.Split(value, ",") {
+					if name = strings.TrimSpace(name); name != "" {
+						filter.Exchanges[strings.ToLower(name)] = struct{}{}
+					}
+				}
+			default:
+				return filter, errorf("err.unknown_param", key)
+			}
+			continue
+		}
+
+		switch strings.ToLower(token) {
+		case "positive", "pos", "+":
+			filter.Direction = directionPositive
+		case "negative", "neg", "-":
+			filter.Direction = directionNegative
+		default:
+			n, err := strconv.Atoi(token)
+			if err != nil || n <= 0 {
+				return filter, errorf("err.unknown_arg", token)
+			}
+			filter.Limit = n
+		}
+	}
+	return filter, nil
+}
+
+// parseAmount разбирает сумму с необязательным суффиксом: 500K, 5M, 1.5B, $10M
+func parseAmount(value string) (float64, error) {
+	value = strings.ToUpper(strings.TrimPrefix(strings.TrimSpace(value), "$"))
+	multiplier := 1.0
+	switch {
+	case strings.HasSuffix(value, "K"):
+		multiplier = 1e3
+	case strings.HasSuffix(value, "M"):
+		multiplier = 1e6
+	case strings.HasSuffix(value, "B"):
+		multiplier = 1e9
+	}
+	if multiplier != 1 {
+		value = value[:len(value)-1]
+	}
+	amount, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, err
+	}
+	if amount < 0 {
+		return 0, fmt.Errorf("сумма не может быть отрицательной")
+	}
+	return amount * multiplier, nil
+}
+
+// formatAmount форматирует сумму в долларах с суффиксом K/M/B
+func formatAmount(amount float64) string {
+	switch {
+	case amount >= 1e9:
+		return fmt.Sprintf("$%.1fB", amount/1e9)
+	case amount >= 1e6:
+		return fmt.Sprintf("$%.1fM", amount/1e6)
+	case amount >= 1e3:
+		return fmt.Sprintf("$%.1fK", amount/1e3)
+	}
+	return fmt.Sprintf("$%.0f", amount)
+}
+
+// matchExchange проверяет, входит ли биржа в фильтр
+func (f rateFilter) matchExchange(exchangeName string) bool {
+	if len(f.Exchanges) == 0 {
+		return true
+	}
+	_, ok := f.Exchanges[strings.ToLower(exchangeName)]
+	return ok
+}
+
+// match проверяет, проходит ли ставка фильтр по направлению и объёму
+func (f rateFilter) match(rate exchanges.FundingRate, prices priceIndex) bool {
+	if f.Direction == directionPositive && rate.Rate <= 0 || f.Direction == directionNegative && rate.Rate >= 0 {
+		return false
+	}
+	return prices.passesMinVolume(rate, f.MinVolume)
+}
+
+// addExchanges добавляет в фильтр биржи из аргумента вида binance,bybit или exchange=binance,bybit
+func (f *rateFilter) addExchanges(arg string) error {
+	names := arg
+	if key, value, ok := strings.Cut(arg, "="); ok {
+		if !strings.EqualFold(key, "exchange") && !strings.EqualFold(key, "ex") {
+			return errorf("err.unknown_param", key)
+		}
+		names = value
+	}
+	if f.Exchanges == nil {
+		f.Exchanges = make(map[string]struct{})
+	}
+	for _, name := range strings.Split(names, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			f.Exchanges[strings.ToLower(name)] = struct{}{}
+		}
+	}
+	return nil
+}
+
+// validateExchanges проверяет, что все биржи из фильтра существуют
+func (f rateFilter) validateExchanges(known []exchanges.Exchange) error {
+	for name := range f.Exchanges {
+		found := false
+		for _, ex := range known {
+			if strings.EqualFold(ex.GetName(), name) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			names := make([]string, 0, len(known))
+			for _, ex := range known {
+				names = append(names, strings.ToLower(ex.GetName()))
+			}
+			return errorf("err.unknown_exchange", name, strings.Join(names, ", "))
+		}
+	}
+	return nil
+}
+
+// describe описывает активные условия фильтра для заголовка ответа
+func (f rateFilter) describe(lang string) string {
+	var parts []string
+	switch f.Direction {
+	case directionPositive:
+		parts = append(parts, tr(lang, "filter.positive"))
+	case directionNegative:
+		parts = append(parts, tr(lang, "filter.negative"))
+	}
+	if f.MinVolume > 0 {
+		parts = append(parts, tr(lang, "filter.min_volume", formatAmount(f.MinVolume)))
+	}
+	if len(f.Exchanges) > 0 {
+		names := make([]string, 0, len(f.Exchanges))
+		for name := range f.Exchanges {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		parts = append(parts, tr(lang, "filter.exchanges", strings.Join(names, ", ")))
+	}
+	return strings.Join(parts, ", ")
+}
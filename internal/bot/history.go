@@ -0,0 +1,256 @@
+package bot
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	exchanges "github.com/petrixs/cr-exchanges"
+)
+
+const (
+	defaultHistoryDir      = "history"
+	defaultHistoryInterval = 30 * time.Minute
+	defaultHistoryDays     = 14
+	// historyDayLayout имя файла истории за сутки (UTC)
+	historyDayLayout = "2006-01-02"
+)
+
+// historyHeader заголовок CSV-файла истории
+var historyHeader = []string{"time", "exchange", "symbol", "rate", "next_funding"}
+
+// historyPoint снимок ставки контракта в момент записи
+type historyPoint struct {
+	Time     time.Time
+	Exchange string
+	Symbol   string
+	Rate     float64
+	// NextFunding время ближайшей выплаты на момент снимка; нулевое, если биржа его не отдала
+	NextFunding time.Time
+}
+
+// rateHistory локальная история ставок: снимки всех ставок раз в interval,
+// по CSV-файлу на сутки в каталоге dir. Файлы старше retention удаляются.
+type rateHistory struct {
+	dir       string
+	interval  time.Duration
+	retention time.Duration
+
+	mu         sync.Mutex
+	lastRecord time.Time
+	lastPrune  time.Time
+}
+
+// newRateHistory создаёт историю с настройками из HISTORY_DIR, HISTORY_INTERVAL и HISTORY_DAYS
+func newRateHistory() *rateHistory {
+	h := &rateHistory{
+		dir:       defaultHistoryDir,
+		interval:  defaultHistoryInterval,
+		retention: defaultHistoryDays * 24 * time.Hour,
+	}
+	if dir := os.Getenv("HISTORY_DIR"); dir != "" {
+		h.dir = dir
+	}
+	if val := os.Getenv("HISTORY_INTERVAL"); val != "" {
+		if interval, err := time.ParseDuration(val); err == nil && interval > 0 {
+			h.interval = interval
+		} else {
+			log.Printf("Некорректный HISTORY_INTERVAL %q, использую %v", val, h.interval)
+		}
+	}
+	if val := os.Getenv("HISTORY_DAYS"); val != "" {
+		if days, err := strconv.Atoi(val); err == nil && days > 0 {
+			h.retention = time.Duration(days) * 24 * time.Hour
+		} else {
+			log.Printf("Некорректный HISTORY_DAYS %q, храню историю %d дней", val, defaultHistoryDays)
+		}
+	}
+	return h
+}
+
+// record сохраняет снимок ставок, если с прошлой записи прошло не меньше interval
+func (h *rateHistory) record(rates map[string][]exchanges.FundingRate, now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if now.Sub(h.lastRecord) < h.interval {
+		return
+	}
+	if err := h.write(rates, now); err != nil {
+		log.Printf("Ошибка записи истории ставок: %v", err)
+		return
+	}
+	h.lastRecord = now
+
+	if now.Sub(h.lastPrune) >= 24*time.Hour {
+		h.prune(now)
+		h.lastPrune = now
+	}
+}
+
+// write дописывает снимок в файл текущих суток
+func (h *rateHistory) write(rates map[string][]exchanges.FundingRate, now time.Time) error {
+	if err := os.MkdirAll(h.dir, 0o755); err != nil {
+		return err
+	}
+	path := h.dayFile(now)
+	_, statErr := os.Stat(path)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	if errors.Is(statErr, os.ErrNotExist) {
+		w.Write(historyHeader)
+	}
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	count := 0
+	for exchangeName, exchangeRates := range rates {
+		for _, rate := range exchangeRates {
+			var nextFunding int64
+			if t, ok := nextFundingTime(rate); ok {
+				nextFunding = t.Unix()
+			}
+			w.Write([]string{
+				timestamp,
+				exchangeName,
+				rate.Symbol,
+				strconv.FormatFloat(rate.Rate, 'g', -1, 64),
+				strconv.FormatInt(nextFunding, 10),
+			})
+			count++
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	log.Printf("В историю записано %d ставок (%s)", count, path)
+	return nil
+}
+
+// prune удаляет файлы истории старше retention
+func (h *rateHistory) prune(now time.Time) {
+	entries, err := os.ReadDir(h.dir)
+	if err != nil {
+		return
+	}
+	cutoff := now.UTC().Add(-h.retention).Truncate(24 * time.Hour)
+	for _, entry := range entries {
+		day, err := time.Parse(historyDayLayout, strings.TrimSuffix(entry.Name(), ".csv"))
+		if err != nil || !day.Before(cutoff) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(h.dir, entry.Name())); err != nil {
+			log.Printf("Не удалось удалить старый файл истории %s: %v", entry.Name(), err)
+		}
+	}
+}
+
+// dayFile путь к файлу истории за сутки, в которые попадает t
+func (h *rateHistory) dayFile(t time.Time) string {
+	return filepath.Join(h.dir, t.UTC().Format(historyDayLayout)+".csv")
+}
+
+// query читает снимки за период [from, to], отобранные match по бирже и тикеру, в порядке времени
+func (h *rateHistory) query(from, to time.Time, match func(exchangeName, symbol string) bool) ([]historyPoint, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var points []historyPoint
+	for day := from.UTC().Truncate(24 * time.Hour); !day.After(to); day = day.AddDate(0, 0, 1) {
+		dayPoints, err := readHistoryFile(h.dayFile(day), from, to, match)
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, dayPoints...)
+	}
+	sort.SliceStable(points, func(i, j int) bool {
+		return points[i].Time.Before(points[j].Time)
+	})
+	return points, nil
+}
+
+// readHistoryFile читает один файл истории; отсутствующий файл — пустая история за эти сутки
+func readHistoryFile(path string, from, to time.Time, match func(exchangeName, symbol string) bool) ([]historyPoint, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	r := csv.NewReader(file)
+	r.FieldsPerRecord = len(historyHeader)
+	r.ReuseRecord = true
+
+	var points []historyPoint
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				// Повреждённая или недописанная строка — пропускаем
+				continue
+			}
+			return nil, fmt.Errorf("чтение %s: %w", path, err)
+		}
+		if record[0] == historyHeader[0] || !match(record[1], record[2]) {
+			continue
+		}
+
+		unix, err := strconv.ParseInt(record[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		t := time.Unix(unix, 0)
+		if t.Before(from) || t.After(to) {
+			continue
+		}
+		rate, err := strconv.ParseFloat(record[3], 64)
+		if err != nil {
+			continue
+		}
+		point := historyPoint{Time: t, Exchange: record[1], Symbol: record[2], Rate: rate}
+		if next, err := strconv.ParseInt(record[4], 10, 64); err == nil && next > 0 {
+			point.NextFunding = time.Unix(next, 0)
+		}
+		points = append(points, point)
+	}
+	return points, nil
+}
+
+// parsePeriod разбирает длину периода: 7d, 24h, 90m, 2w
+func parsePeriod(value string) (time.Duration, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(value, suffix); ok {
+			count, err := strconv.Atoi(n)
+			if err != nil || count <= 0 {
+				return 0, errorf("err.bad_period", value)
+			}
+			return time.Duration(count) * unit, nil
+		}
+	}
+	period, err := time.ParseDuration(value)
+	if err != nil || period <= 0 {
+		return 0, errorf("err.bad_period", value)
+	}
+	return period, nil
+}
@@ -0,0 +1,218 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Поддерживаемые языки интерфейса
+const (
+	langRU = "ru"
+	langEN = "en"
+	langUK = "uk"
+)
+
+// supportedLangs языки в порядке отображения в /lang
+var supportedLangs = []struct {
+	Code string
+	Name string
+}{
+	{langRU, "Русский"},
+	{langEN, "English"},
+	{langUK, "Українська"},
+}
+
+// normalizeLang приводит language_code Telegram (ru, en-US, uk) к поддерживаемому языку; "" — язык не поддерживается
+func normalizeLang(code string) string {
+	code, _, _ = strings.Cut(strings.ToLower(strings.TrimSpace(code)), "-")
+	for _, lang := range supportedLangs {
+		if lang.Code == code {
+			return code
+		}
+	}
+	return ""
+}
+
+// defaultLang язык по умолчанию из DEFAULT_LANG, иначе русский
+func defaultLang() string {
+	if lang := normalizeLang(os.Getenv("DEFAULT_LANG")); lang != "" {
+		return lang
+	}
+	return langRU
+}
+
+// chatLang возвращает язык чата для рассылок: выбранный через /lang, определённый по
+// language_code собеседника в личном чате или язык по умолчанию
+func chatLang(chatID int64) string {
+	return userLang(chatID, nil)
+}
+
+// userLang возвращает язык ответа пользователю в чате: выбранный через /lang,
+// иначе по language_code пользователя, иначе язык чата по умолчанию
+func userLang(chatID int64, user *tgbotapi.User) string {
+	settings := getChatSettings(chatID)
+	if settings.Lang != "" {
+		return settings.Lang
+	}
+	if user != nil {
+		if lang := normalizeLang(user.LanguageCode); lang != "" {
+			return lang
+		}
+	}
+	if settings.DetectedLang != "" {
+		return settings.DetectedLang
+	}
+	return defaultLang()
+}
+
+// msgLang возвращает язык ответа на сообщение
+func msgLang(msg *tgbotapi.Message) string {
+	return userLang(msg.Chat.ID, msg.From)
+}
+
+// rememberLanguage запоминает язык собеседника в личном чате, чтобы рассылки шли на нём же
+func rememberLanguage(msg *tgbotapi.Message) {
+	if !msg.Chat.IsPrivate() || msg.From == nil {
+		return
+	}
+	lang := normalizeLang(msg.From.LanguageCode)
+	if lang == "" || getChatSettings(msg.Chat.ID).DetectedLang == lang {
+		return
+	}
+	updateChatSettings(msg.Chat.ID, func(settings *ChatSettings) {
+		settings.DetectedLang = lang
+	})
+}
+
+// tr возвращает перевод сообщения из каталога; если перевода нет — русский вариант
+func tr(lang, key string, args ...any) string {
+	return formatMessage(lookup(lang, key), args...)
+}
+
+// trn выбирает форму множественного числа для n. Формы в каталоге разделены "|":
+// для русского и украинского one|few|many, для английского one|other.
+// Без аргументов в шаблон подставляется само n.
+func trn(lang, key string, n int, args ...any) string {
+	forms := strings.Split(lookup(lang, key), "|")
+	form := forms[len(forms)-1]
+	if i := pluralIndex(lang, n); i < len(forms) {
+		form = forms[i]
+	}
+	if len(args) == 0 {
+		args = []any{n}
+	}
+	return formatMessage(form, args...)
+}
+
+// lookup ищет шаблон сообщения в каталоге
+func lookup(lang, key string) string {
+	translations, ok := messages[key]
+	if !ok {
+		log.Printf("Нет сообщения %q в каталоге", key)
+		return key
+	}
+	if text, ok := translations[lang]; ok {
+		return text
+	}
+	return translations[langRU]
+}
+
+// formatMessage подставляет аргументы в шаблон; шаблоны без аргументов возвращаются как есть
+func formatMessage(template string, args ...any) string {
+	if len(args) == 0 {
+		return template
+	}
+	return fmt.Sprintf(template, args...)
+}
+
+// pluralIndex возвращает номер формы множественного числа для языка
+func pluralIndex(lang string, n int) int {
+	if n < 0 {
+		n = -n
+	}
+	if lang == langEN {
+		if n == 1 {
+			return 0
+		}
+		return 1
+	}
+	switch {
+	case n%10 == 1 && n%100 != 11:
+		return 0
+	case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+		return 1
+	default:
+		return 2
+	}
+}
+
+// localizedError ошибка разбора аргументов команды, текст которой переводится на язык чата
+type localizedError struct {
+	key  string
+	args []any
+}
+
+// errorf создаёт ошибку по ключу каталога
+func errorf(key string, args ...any) error {
+	return localizedError{key: key, args: args}
+}
+
+func (e localizedError) Error() string {
+	return tr(langRU, e.key, e.args...)
+}
+
+// localize возвращает текст ошибки на языке чата
+func localize(lang string, err error) string {
+	var localized localizedError
+	if errors.As(err, &localized) {
+		return tr(lang, localized.key, localized.args...)
+	}
+	return err.Error()
+}
+
+// handleLang показывает или задаёт язык чата: /lang en, /lang auto
+func (b *Bot) handleLang(r *request) {
+	msg := r.Msg
+	args := strings.ToLower(r.Arg(0))
+	if args == "" {
+		options := make([]string, 0, len(supportedLangs))
+		for _, lang := range supportedLangs {
+			options = append(options, lang.Code+" — "+lang.Name)
+		}
+		lang := msgLang(msg)
+		b.reply(msg, tr(lang, "lang.current", langName(lang), strings.Join(options, "\n")))
+		return
+	}
+
+	if !b.canChangeSettings(msg) {
+		b.reply(msg, tr(msgLang(msg), "lang.admin_only"))
+		return
+	}
+
+	lang := normalizeLang(args)
+	if lang == "" && args != "auto" {
+		b.reply(msg, tr(msgLang(msg), "lang.unknown", escapeHTML(args)))
+		return
+	}
+
+	updateChatSettings(msg.Chat.ID, func(settings *ChatSettings) {
+		settings.Lang = lang
+	})
+	lang = msgLang(msg)
+	b.reply(msg, tr(lang, "lang.set", langName(lang)))
+}
+
+// langName возвращает название языка
+func langName(code string) string {
+	for _, lang := range supportedLangs {
+		if lang.Code == code {
+			return lang.Name
+		}
+	}
+	return code
+}
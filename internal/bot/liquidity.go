@@ -0,0 +1,97 @@
+package bot
+
+import (
+	"sort"
+	"strings"
+
+	exchanges "github.com/petrixs/cr-exchanges"
+)
+
+// priceIndex оценка цены одной монеты по данным бирж, которые отдают объём и в монетах, и в USDT
+type priceIndex map[string]float64
+
+// buildPriceIndex считает медианную цену каждой монеты как VolumeUSDT24h / Volume24h. Объём в монетах
+// считается в единицах контракта, поэтому цена 1000PEPE делится на 1000 и сравнима с ценой PEPE.
+func buildPriceIndex(rates map[string][]exchanges.FundingRate) priceIndex {
+	samples := make(map[string][]float64)
+	for _, exchangeRates := range rates {
+		for _, rate := range exchangeRates {
+			if rate.VolumeUSDT24h > 0 && rate.Volume24h > 0 {
+				base, multiplier := symbolBase(rate.Symbol)
+				samples[base] = append(samples[base], rate.VolumeUSDT24h/rate.Volume24h/multiplier)
+			}
+		}
+	}
+
+	prices := make(priceIndex, len(samples))
+	for base, values := range samples {
+		sort.Float64s(values)
+		prices[base] = values[len(values)/2]
+	}
+	return prices
+}
+
+// usdVolume возвращает суточный объём в долларах: VolumeUSDT24h, а если биржа его не отдаёт —
+// Volume24h, умноженный на цену с других бирж. 0 означает, что объём неизвестен.
+func (p priceIndex) usdVolume(rate exchanges.FundingRate) float64 {
+	if rate.VolumeUSDT24h > 0 {
+		return rate.VolumeUSDT24h
+	}
+	if rate.Volume24h > 0 {
+		base, multiplier := symbolBase(rate.Symbol)
+		if price, ok := p[base]; ok {
+			return rate.Volume24h * price * multiplier
+		}
+	}
+	return 0
+}
+
+// passesMinVolume проверяет ликвидность; при включённом фильтре ставки без данных об объёме скрываются
+func (p priceIndex) passesMinVolume(rate exchanges.FundingRate, minVolume float64) bool {
+	return minVolume <= 0 || p.usdVolume(rate) >= minVolume
+}
+
+// getMinVolume возвращает минимальный суточный объём в долларах для чата (0 — фильтр выключен)
+func getMinVolume(chatID int64) float64 {
+	return getChatSettings(chatID).MinVolume
+}
+
+// handleMinVolume показывает или задаёт минимальный объём: /minvolume 5M, /minvolume off
+func (b *Bot) handleMinVolume(r *request) {
+	msg := r.Msg
+	lang := msgLang(msg)
+	args := r.Arg(0)
+	if args == "" {
+		text := tr(lang, "minvolume.off")
+		if minVolume := getMinVolume(msg.Chat.ID); minVolume > 0 {
+			text = tr(lang, "minvolume.current", formatAmount(minVolume))
+		}
+		b.reply(msg, text+tr(lang, "minvolume.usage"))
+		return
+	}
+
+	if !b.canChangeSettings(msg) {
+		b.reply(msg, tr(lang, "minvolume.admin_only"))
+		return
+	}
+
+	var minVolume float64
+	if !strings.EqualFold(args, "off") {
+		amount, err := parseAmount(args)
+		if err != nil {
+			b.reply(msg, tr(lang, "minvolume.error"))
+			return
+		}
+		minVolume = amount
+	}
+
+	updateChatSettings(msg.Chat.ID, func(settings *ChatSettings) {
+		settings.MinVolume = minVolume
+	})
+
+	if minVolume == 0 {
+		b.reply(msg, tr(lang, "minvolume.off"))
+		return
+	}
+	b.reply(msg, tr(lang, "minvolume.set", formatAmount(minVolume)))
+}
@@ -0,0 +1,34 @@
+package bot
+
+import (
+	"math"
+	"testing"
+
+	exchanges "github.com/petrixs/cr-exchanges"
+)
+
+func TestPriceIndexScalesMultiplierContracts(t *testing.T) {
+	// PEPE стоит 0.00001 USDT, контракт 1000PEPE — 0.01 USDT
+	rates := map[string][]exchanges.FundingRate{
+		"Binance": {{Symbol: "1000PEPEUSDT", Volume24h: 1e9, VolumeUSDT24h: 1e7}},
+		"OKX":     {{Symbol: "PEPE-USDT-SWAP", Volume24h: 1e12, VolumeUSDT24h: 1e7}},
+		"Bybit":   {{Symbol: "PEPEUSDT", Volume24h: 2e12, VolumeUSDT24h: 2e7}},
+	}
+	prices := buildPriceIndex(rates)
+	if price := prices["PEPE"]; math.Abs(price-0.00001) > 1e-12 {
+		t.Fatalf("цена PEPE = %v, ожидается 0.00001", price)
+	}
+
+	// Биржи без объёма в USDT: объём пересчитывается через цену одной монеты
+	for _, tc := range []struct {
+		rate exchanges.FundingRate
+		want float64
+	}{
+		{exchanges.FundingRate{Symbol: "kPEPE", Volume24h: 5e8}, 5e6},
+		{exchanges.FundingRate{Symbol: "PEPEUSDT", Volume24h: 5e11}, 5e6},
+	} {
+		if got := prices.usdVolume(tc.rate); math.Abs(got-tc.want) > 1 {
+			t.Errorf("%s: объём %v, ожидается %v", tc.rate.Symbol, got, tc.want)
+		}
+	}
+}
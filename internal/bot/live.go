@@ -0,0 +1,169 @@
+package bot
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Живое табло: одно закреплённое сообщение в чате, которое бот редактирует после каждого
+// обновления ставок. ID сообщения хранится в настройках чата, поэтому табло переживает перезапуск;
+// если сообщение удалили, табло публикуется заново.
+
+// liveRowLimit ставок биржи на табло; если табло не помещается в одно сообщение, строк становится меньше
+const liveRowLimit = 10
+
+// handleLive включает табло в чате и теме, откуда пришла команда: /live, /live off
+func (b *Bot) handleLive(r *request) {
+	msg := r.Msg
+	lang := msgLang(msg)
+	if !b.canChangeSettings(msg) {
+		b.reply(msg, tr(lang, "live.admin_only"))
+		return
+	}
+
+	chatID := msg.Chat.ID
+	previous := getChatSettings(chatID)
+	switch strings.ToLower(r.Arg(0)) {
+	case "", "on":
+		if previous.LiveMessageID != 0 {
+			b.unpinMessage(chatID, previous.LiveMessageID)
+		}
+		target := chatTarget{ChatID: chatID, ThreadID: b.threadOf(msg)}
+		if err := b.postLiveBoard(target, b.liveBoardText(chatID, time.Now())); err != nil {
+			log.Printf("Не удалось опубликовать табло в чате %d: %v", chatID, err)
+			b.reply(msg, tr(lang, "live.error"))
+			return
+		}
+		b.reply(msg, tr(lang, "live.on", formatDuration(ratesUpdateInterval, lang)))
+	case "off":
+		if !previous.Live {
+			b.reply(msg, tr(lang, "live.not_on"))
+			return
+		}
+		updateChatSettings(chatID, func(settings *ChatSettings) {
+			settings.Live = false
+			settings.LiveMessageID = 0
+			settings.LiveThreadID = 0
+		})
+		if previous.LiveMessageID != 0 {
+			b.unpinMessage(chatID, previous.LiveMessageID)
+		}
+		b.reply(msg, tr(lang, "live.off"))
+	default:
+		b.reply(msg, tr(lang, "live.usage"))
+	}
+}
+
+// liveBoardText текст табло по порогам и часовому поясу чата с временем обновления
+func (b *Bot) liveBoardText(chatID int64, now time.Time) string {
+	to := b.telegramRecipient(chatID)
+	lang := to.Lang
+	rates := b.cache.GetAllRates()
+	footer := "\n" + tr(lang, "live.updated", now.In(to.Loc).Format("02.01 15:04:05 MST"))
+	if len(rates) == 0 {
+		return tr(lang, "live.header") + "\n" + tr(lang, "rates.empty") + footer
+	}
+
+	// Время обновления остаётся всегда: при нехватке места обрезается список ставок
+	room := telegramTextLimit - utf16Len(plainText(footer))
+	prices := buildPriceIndex(rates)
+	opts := to.reportOptions(now)
+	opts.Digest = false
+	for limit := liveRowLimit; ; limit /= 2 {
+		opts.RowLimit = limit
+		text := tr(lang, "live.header") + "\n" + renderTelegramHTML(buildRatesReport(rates, prices, opts))
+		parts := splitHTML(text, room)
+		if len(parts) <= 1 {
+			return text + footer
+		}
+		if limit == 1 {
+			return parts[0] + footer
+		}
+	}
+}
+
+// postLiveBoard публикует табло, закрепляет его и запоминает ID сообщения. Без очереди повтора:
+// иначе табло, опубликованное повтором, осталось бы без сохранённого ID и появилось бы дважды.
+func (b *Bot) postLiveBoard(target chatTarget, text string) error {
+	messageID, err := b.deliver([]*outgoingMessage{{Target: target, Text: text, Priority: priorityBroadcast, NoRetry: true}})
+	if err != nil {
+		return err
+	}
+	updateChatSettings(target.ChatID, func(settings *ChatSettings) {
+		settings.Live = true
+		settings.LiveMessageID = messageID
+		settings.LiveThreadID = target.ThreadID
+	})
+	pin := tgbotapi.PinChatMessageConfig{ChatID: target.ChatID, MessageID: messageID, DisableNotification: true}
+	if _, err := b.bot.Request(pin); err != nil {
+		// Без прав на закрепление табло всё равно обновляется
+		log.Printf("Не удалось закрепить табло в чате %d: %v", target.ChatID, err)
+	}
+	return nil
+}
+
+// unpinMessage открепляет старое табло; ошибки не важны — сообщение могли уже удалить
+func (b *Bot) unpinMessage(chatID int64, messageID int) {
+	if _, err := b.bot.Request(tgbotapi.UnpinChatMessageConfig{ChatID: chatID, MessageID: messageID}); err != nil {
+		log.Printf("Не удалось открепить табло в чате %d: %v", chatID, err)
+	}
+}
+
+// liveChats чаты с включённым табло
+func liveChats() map[int64]ChatSettings {
+	chatSettingsLock.Lock()
+	defer chatSettingsLock.Unlock()
+	chats := make(map[int64]ChatSettings)
+	for chatID, settings := range chatSettings {
+		if settings.Live {
+			chats[chatID] = *settings
+		}
+	}
+	return chats
+}
+
+// startLiveBoardLoop обновляет табло после каждого обновления ставок из updates
+func (b *Bot) startLiveBoardLoop(updates chan time.Time) {
+	for now := range updates {
+		b.refreshLiveBoards(now)
+	}
+}
+
+// refreshLiveBoards редактирует табло всех чатов; правки идут через диспетчер с приоритетом рассылки
+func (b *Bot) refreshLiveBoards(now time.Time) {
+	for chatID, settings := range liveChats() {
+		if !chatHasAccess(chatID) {
+			continue
+		}
+		b.refreshLiveBoard(chatID, settings, now)
+	}
+}
+
+func (b *Bot) refreshLiveBoard(chatID int64, settings ChatSettings, now time.Time) {
+	target := chatTarget{ChatID: chatID, ThreadID: settings.LiveThreadID}
+	text := b.liveBoardText(chatID, now)
+	if settings.LiveMessageID != 0 {
+		_, err := b.deliver([]*outgoingMessage{{
+			Target:        target,
+			Text:          text,
+			EditMessageID: settings.LiveMessageID,
+			Priority:      priorityBroadcast,
+			// Устаревший текст повторять незачем, табло обновится со следующими ставками
+			NoRetry: true,
+		}})
+		if !errors.Is(err, errMessageGone) {
+			if err != nil {
+				log.Printf("Ошибка обновления табло в чате %d: %v", chatID, err)
+			}
+			return
+		}
+		log.Printf("Табло в чате %d удалено, публикую заново", chatID)
+	}
+	if err := b.postLiveBoard(target, text); err != nil {
+		log.Printf("Не удалось опубликовать табло в чате %d: %v", chatID, err)
+	}
+}
@@ -0,0 +1,1403 @@
+package bot
+
+// messages каталог сообщений бота: ключ → перевод на каждый язык.
+// Формы множественного числа разделяются "|" (см. trn).
+var messages = map[string]map[string]string{
+	// Общие
+	"error": {
+		langRU: "Ошибка: %s",
+		langEN: "Error: %s",
+		langUK: "Помилка: %s",
+	},
+	"error.internal": {
+		langRU: "Внутренняя ошибка при выполнении команды. Попробуйте позже.",
+		langEN: "Internal error while running the command. Please try again later.",
+		langUK: "Внутрішня помилка під час виконання команди. Спробуйте пізніше.",
+	},
+	"ratelimit.exceeded": {
+		langRU: "Слишком много команд подряд. Подождите несколько секунд.",
+		langEN: "Too many commands in a row. Please wait a few seconds.",
+		langUK: "Забагато команд поспіль. Зачекайте кілька секунд.",
+	},
+	"args.too_many": {
+		langRU: "Слишком много аргументов для /%s.\n\n%s",
+		langEN: "Too many arguments for /%s.\n\n%s",
+		langUK: "Забагато аргументів для /%s.\n\n%s",
+	},
+	"admin.only": {
+		langRU: "Эта команда доступна только администраторам.",
+		langEN: "This command is only available to administrators.",
+		langUK: "Ця команда доступна лише адміністраторам.",
+	},
+
+	// /start
+	"start.text": {
+		langRU: "Привет! Я бот для мониторинга ставок фандинга.\n" +
+			"Доступные команды:\n%s\n\n" +
+			"Подробнее о команде: /help команда",
+		langEN: "Hi! I monitor funding rates across exchanges.\n" +
+			"Available commands:\n%s\n\n" +
+			"Details on a command: /help command",
+		langUK: "Привіт! Я бот для моніторингу ставок фандингу.\n" +
+			"Доступні команди:\n%s\n\n" +
+			"Докладніше про команду: /help команда",
+	},
+	"help.list": {
+		langRU: "Доступные команды:\n%s\n\nПодробнее о команде и примеры: /help команда, например /help threshold",
+		langEN: "Available commands:\n%s\n\nDetails and examples: /help command, e.g. /help threshold",
+		langUK: "Доступні команди:\n%s\n\nДокладніше про команду та приклади: /help команда, наприклад /help threshold",
+	},
+	"help.unknown": {
+		langRU: "Неизвестная команда %s. Список команд: /help",
+		langEN: "Unknown command %s. Command list: /help",
+		langUK: "Невідома команда %s. Список команд: /help",
+	},
+
+	// Подписка
+	"subscribe.admin_only": {
+		langRU: "Подписку в группе могут менять только администраторы.",
+		langEN: "Only administrators can change the subscription in a group.",
+		langUK: "Підписку в групі можуть змінювати лише адміністратори.",
+	},
+	"subscribe.done": {
+		langRU: "Вы успешно подписались на уведомления!",
+		langEN: "You have subscribed to notifications!",
+		langUK: "Ви успішно підписалися на сповіщення!",
+	},
+	"unsubscribe.done": {
+		langRU: "Вы успешно отписались от уведомлений.",
+		langEN: "You have unsubscribed from notifications.",
+		langUK: "Ви успішно відписалися від сповіщень.",
+	},
+	"chat.welcome": {
+		langRU: "Спасибо за добавление! Отправьте /subscribe, чтобы получать уведомления о ставках фандинга в этот чат.\n" +
+			"Менять настройки могут только администраторы чата.",
+		langEN: "Thanks for adding me! Send /subscribe to receive funding rate notifications in this chat.\n" +
+			"Only chat administrators can change the settings.",
+		langUK: "Дякую за додавання! Надішліть /subscribe, щоб отримувати сповіщення про ставки фандингу в цей чат.\n" +
+			"Змінювати налаштування можуть лише адміністратори чату.",
+	},
+
+	// Ставки
+	"rates.empty": {
+		langRU: "Нет доступных ставок фандинга",
+		langEN: "No funding rates available",
+		langUK: "Немає доступних ставок фандингу",
+	},
+	"rates.none_above": {
+		langRU: "<i>Нет доступных ставок фандинга, превышающих порог</i>",
+		langEN: "<i>No funding rates above the threshold</i>",
+		langUK: "<i>Немає ставок фандингу, що перевищують поріг</i>",
+	},
+	"rates.more": {
+		langRU: "<i>... и ещё %d запись</i>|<i>... и ещё %d записи</i>|<i>... и ещё %d записей</i>",
+		langEN: "<i>... and %d more entry</i>|<i>... and %d more entries</i>",
+		langUK: "<i>... і ще %d запис</i>|<i>... і ще %d записи</i>|<i>... і ще %d записів</i>",
+	},
+	"rates.payment": {
+		langRU: "(выплата: %s)",
+		langEN: "(payment: %s)",
+		langUK: "(виплата: %s)",
+	},
+	"rates.unknown_time": {
+		langRU: "Неизвестно",
+		langEN: "unknown",
+		langUK: "Невідомо",
+	},
+	"rates.page_header": {
+		langRU: "<b>📈 %s</b> · %s · стр. %d/%d\n",
+		langEN: "<b>📈 %s</b> · %s · page %d/%d\n",
+		langUK: "<b>📈 %s</b> · %s · стор. %d/%d\n",
+	},
+	"rates.view_empty": {
+		langRU: "<i>Нет ставок, превышающих порог</i>",
+		langEN: "<i>No rates above the threshold</i>",
+		langUK: "<i>Немає ставок, що перевищують поріг</i>",
+	},
+	"rates.shown": {
+		langRU: "<i>Показано %d–%d из %d (порог %s",
+		langEN: "<i>Showing %d–%d of %d (threshold %s",
+		langUK: "<i>Показано %d–%d з %d (поріг %s",
+	},
+	"rates.min_volume": {
+		langRU: ", объём ≥ %s",
+		langEN: ", volume ≥ %s",
+		langUK: ", обсяг ≥ %s",
+	},
+	"digest.header": {
+		langRU: "<b>📰 Дайджест ставок фандинга</b>",
+		langEN: "<b>📰 Funding rate digest</b>",
+		langUK: "<b>📰 Дайджест ставок фандингу</b>",
+	},
+	"sort.abs": {
+		langRU: "по модулю ставки",
+		langEN: "by absolute rate",
+		langUK: "за модулем ставки",
+	},
+	"sort.pos": {
+		langRU: "положительные",
+		langEN: "positive",
+		langUK: "додатні",
+	},
+	"sort.neg": {
+		langRU: "отрицательные",
+		langEN: "negative",
+		langUK: "від'ємні",
+	},
+	"sort.vol": {
+		langRU: "по объёму",
+		langEN: "by volume",
+		langUK: "за обсягом",
+	},
+	"sort.next": {
+		langRU: "по времени выплаты",
+		langEN: "by payment time",
+		langUK: "за часом виплати",
+	},
+	"callback.unknown_button": {
+		langRU: "Неизвестная кнопка",
+		langEN: "Unknown button",
+		langUK: "Невідома кнопка",
+	},
+	"callback.no_access": {
+		langRU: "Нет доступа",
+		langEN: "Access denied",
+		langUK: "Немає доступу",
+	},
+
+	// Пороги
+	"threshold.current": {
+		langRU: "Текущий порог: %s (источник: %s)\n" +
+			"Для установки нового порога используйте команду /threshold X.XXX (в процентах), " +
+			"отдельно для направлений — /threshold +0.05 -0.2, " +
+			"только одно направление — /threshold positive, negative или both",
+		langEN: "Current threshold: %s (source: %s)\n" +
+			"To set a new threshold use /threshold X.XXX (in percent), " +
+			"separately per direction — /threshold +0.05 -0.2, " +
+			"a single direction only — /threshold positive, negative or both",
+		langUK: "Поточний поріг: %s (джерело: %s)\n" +
+			"Щоб встановити новий поріг, використовуйте команду /threshold X.XXX (у відсотках), " +
+			"окремо для напрямків — /threshold +0.05 -0.2, " +
+			"лише один напрямок — /threshold positive, negative або both",
+	},
+	"threshold.source.user": {
+		langRU: "настройка чата",
+		langEN: "chat setting",
+		langUK: "налаштування чату",
+	},
+	"threshold.source.default": {
+		langRU: "по умолчанию",
+		langEN: "default",
+		langUK: "за замовчуванням",
+	},
+	"threshold.admin_only": {
+		langRU: "Порог в группе могут менять только администраторы.",
+		langEN: "Only administrators can change the threshold in a group.",
+		langUK: "Поріг у групі можуть змінювати лише адміністратори.",
+	},
+	"threshold.error": {
+		langRU: "Ошибка: %s. Пример: /threshold 0.1 или /threshold +0.05 -0.2",
+		langEN: "Error: %s. Example: /threshold 0.1 or /threshold +0.05 -0.2",
+		langUK: "Помилка: %s. Приклад: /threshold 0.1 або /threshold +0.05 -0.2",
+	},
+	"threshold.set": {
+		langRU: "Установлен новый порог: %s",
+		langEN: "New threshold set: %s",
+		langUK: "Встановлено новий поріг: %s",
+	},
+	"threshold.only_positive": {
+		langRU: " (только положительные)",
+		langEN: " (positive only)",
+		langUK: " (лише додатні)",
+	},
+	"threshold.only_negative": {
+		langRU: " (только отрицательные)",
+		langEN: " (negative only)",
+		langUK: " (лише від'ємні)",
+	},
+	"err.bad_threshold": {
+		langRU: "некорректный порог %q",
+		langEN: "invalid threshold %q",
+		langUK: "некоректний поріг %q",
+	},
+
+	// Фильтры
+	"err.bad_volume": {
+		langRU: "некорректный объём %q",
+		langEN: "invalid volume %q",
+		langUK: "некоректний обсяг %q",
+	},
+	"err.unknown_param": {
+		langRU: "неизвестный параметр %q",
+		langEN: "unknown parameter %q",
+		langUK: "невідомий параметр %q",
+	},
+	"err.unknown_arg": {
+		langRU: "неизвестный аргумент %q",
+		langEN: "unknown argument %q",
+		langUK: "невідомий аргумент %q",
+	},
+	"err.unknown_exchange": {
+		langRU: "неизвестная биржа %q, доступны: %s",
+		langEN: "unknown exchange %q, available: %s",
+		langUK: "невідома біржа %q, доступні: %s",
+	},
+	"filter.positive": {
+		langRU: "положительные",
+		langEN: "positive",
+		langUK: "додатні",
+	},
+	"filter.negative": {
+		langRU: "отрицательные",
+		langEN: "negative",
+		langUK: "від'ємні",
+	},
+	"filter.min_volume": {
+		langRU: "объём ≥ %s",
+		langEN: "volume ≥ %s",
+		langUK: "обсяг ≥ %s",
+	},
+	"filter.exchanges": {
+		langRU: "биржи: %s",
+		langEN: "exchanges: %s",
+		langUK: "біржі: %s",
+	},
+
+	// /symbol и /top
+	"symbol.usage": {
+		langRU: "Использование: /symbol BTC (подходит любое написание тикера: BTCUSDT, BTC-USDT-SWAP, XBTUSDTM)",
+		langEN: "Usage: /symbol BTC (any ticker spelling works: BTCUSDT, BTC-USDT-SWAP, XBTUSDTM)",
+		langUK: "Використання: /symbol BTC (підходить будь-яке написання тікера: BTCUSDT, BTC-USDT-SWAP, XBTUSDTM)",
+	},
+	"symbol.not_found": {
+		langRU: "Монета %s не найдена ни на одной бирже",
+		langEN: "Coin %s was not found on any exchange",
+		langUK: "Монету %s не знайдено на жодній біржі",
+	},
+	"symbol.title": {
+		langRU: "<b>🔎 %s</b> на %d бирже\n|<b>🔎 %s</b> на %d биржах\n|<b>🔎 %s</b> на %d биржах\n",
+		langEN: "<b>🔎 %s</b> on %d exchange\n|<b>🔎 %s</b> on %d exchanges\n",
+		langUK: "<b>🔎 %s</b> на %d біржі\n|<b>🔎 %s</b> на %d біржах\n|<b>🔎 %s</b> на %d біржах\n",
+	},
+	"symbol.best_pair": {
+		langRU: "\n<b>Лучшая пара:</b> Long %s / Short %s\nСпред: %.4f%% за 8ч",
+		langEN: "\n<b>Best pair:</b> Long %s / Short %s\nSpread: %.4f%% per 8h",
+		langUK: "\n<b>Найкраща пара:</b> Long %s / Short %s\nСпред: %.4f%% за 8год",
+	},
+	"col.exchange": {
+		langRU: "Биржа",
+		langEN: "Exchange",
+		langUK: "Біржа",
+	},
+	"col.symbol": {
+		langRU: "Тикер",
+		langEN: "Symbol",
+		langUK: "Тікер",
+	},
+	"col.rate": {
+		langRU: "Ставка",
+		langEN: "Rate",
+		langUK: "Ставка",
+	},
+	"col.rate8h": {
+		langRU: "8ч",
+		langEN: "8h",
+		langUK: "8год",
+	},
+	"col.payment": {
+		langRU: "Выплата",
+		langEN: "Payment",
+		langUK: "Виплата",
+	},
+	"col.volume": {
+		langRU: "Объём",
+		langEN: "Volume",
+		langUK: "Обсяг",
+	},
+	"top.error": {
+		langRU: "Ошибка: %s\nИспользование: /top [n] [positive|negative] [minvol=5M] [exchange=binance,bybit]",
+		langEN: "Error: %s\nUsage: /top [n] [positive|negative] [minvol=5M] [exchange=binance,bybit]",
+		langUK: "Помилка: %s\nВикористання: /top [n] [positive|negative] [minvol=5M] [exchange=binance,bybit]",
+	},
+	"top.no_match": {
+		langRU: "<i>Нет ставок, подходящих под фильтр</i>",
+		langEN: "<i>No rates match the filter</i>",
+		langUK: "<i>Немає ставок, що відповідають фільтру</i>",
+	},
+	"top.header": {
+		langRU: "<b>🏆 Топ-%d ставок фандинга</b>",
+		langEN: "<b>🏆 Top %d funding rates</b>",
+		langUK: "<b>🏆 Топ-%d ставок фандингу</b>",
+	},
+
+	// График истории ставок
+	"chart.usage": {
+		langRU: "Укажите монету: /chart BTC, /chart ETH 24h, /chart BTC 7d binance,bybit",
+		langEN: "Specify a coin: /chart BTC, /chart ETH 24h, /chart BTC 7d binance,bybit",
+		langUK: "Вкажіть монету: /chart BTC, /chart ETH 24h, /chart BTC 7d binance,bybit",
+	},
+	"history.too_long": {
+		langRU: "История хранится только %s, выберите период короче.",
+		langEN: "History is kept for %s only, choose a shorter period.",
+		langUK: "Історія зберігається лише %s, оберіть коротший період.",
+	},
+	"chart.no_data": {
+		langRU: "Нет истории ставок %s за этот период. Ставки записываются раз в %s, график появится после первых записей.",
+		langEN: "No rate history for %s in this period. Rates are recorded every %s, the chart will be available after the first records.",
+		langUK: "Немає історії ставок %s за цей період. Ставки записуються раз на %s, графік з'явиться після перших записів.",
+	},
+	"chart.error": {
+		langRU: "Не удалось построить график, попробуйте позже.",
+		langEN: "Could not build the chart, please try again later.",
+		langUK: "Не вдалося побудувати графік, спробуйте пізніше.",
+	},
+	"chart.caption": {
+		langRU: "<b>%s</b>: ставки фандинга за %s в пересчёте на 8 часов.\nКольца — выплаты, время — %s.",
+		langEN: "<b>%s</b>: funding rates over %s, normalized to 8 hours.\nRings mark settlements, time zone %s.",
+		langUK: "<b>%s</b>: ставки фандингу за %s у перерахунку на 8 годин.\nКільця — виплати, час — %s.",
+	},
+
+	// Выгрузка ставок
+	"export.empty": {
+		langRU: "Нет ставок для выгрузки по этим условиям.",
+		langEN: "No rates to export for these conditions.",
+		langUK: "Немає ставок для вивантаження за цими умовами.",
+	},
+	"export.error": {
+		langRU: "Не удалось сформировать выгрузку, попробуйте позже.",
+		langEN: "Could not build the export, please try again later.",
+		langUK: "Не вдалося сформувати вивантаження, спробуйте пізніше.",
+	},
+	"export.too_large": {
+		langRU: "Выгрузка слишком большая (%d МБ), Telegram принимает файлы до 50 МБ. Выберите биржу или период короче.",
+		langEN: "The export is too large (%d MB), Telegram accepts files up to 50 MB. Pick an exchange or a shorter period.",
+		langUK: "Вивантаження завелике (%d МБ), Telegram приймає файли до 50 МБ. Оберіть біржу або коротший період.",
+	},
+	"export.caption": {
+		langRU: "%d ставка|%d ставки|%d ставок",
+		langEN: "%d rate|%d rates",
+		langUK: "%d ставка|%d ставки|%d ставок",
+	},
+
+	// Панель в терминале
+	"sort.symbol": {
+		langRU: "по тикеру",
+		langEN: "by symbol",
+		langUK: "за тікером",
+	},
+	"sort.exchange": {
+		langRU: "по бирже",
+		langEN: "by exchange",
+		langUK: "за біржею",
+	},
+	"tui.loading": {
+		langRU: "загрузка ставок…",
+		langEN: "loading rates…",
+		langUK: "завантаження ставок…",
+	},
+	"tui.updated": {
+		langRU: "%d ставка · обновлено в %s · следующее через %s|%d ставки · обновлено в %s · следующее через %s|%d ставок · обновлено в %s · следующее через %s",
+		langEN: "%d rate · updated at %s · next in %s|%d rates · updated at %s · next in %s",
+		langUK: "%d ставка · оновлено о %s · наступне через %s|%d ставки · оновлено о %s · наступне через %s|%d ставок · оновлено о %s · наступне через %s",
+	},
+	"tui.updating": {
+		langRU: "%d ставка · обновлено в %s · обновление…|%d ставки · обновлено в %s · обновление…|%d ставок · обновлено в %s · обновление…",
+		langEN: "%d rate · updated at %s · updating…|%d rates · updated at %s · updating…",
+		langUK: "%d ставка · оновлено о %s · оновлення…|%d ставки · оновлено о %s · оновлення…|%d ставок · оновлено о %s · оновлення…",
+	},
+	"tui.view": {
+		langRU: "Сортировка: %s · Биржа: %s · Фильтр: %s",
+		langEN: "Sort: %s · Exchange: %s · Filter: %s",
+		langUK: "Сортування: %s · Біржа: %s · Фільтр: %s",
+	},
+	"tui.all": {
+		langRU: "все",
+		langEN: "all",
+		langUK: "усі",
+	},
+	"tui.empty": {
+		langRU: "Нет ставок под эти условия",
+		langEN: "No rates match",
+		langUK: "Немає ставок за цими умовами",
+	},
+	"tui.shown": {
+		langRU: "%d–%d из %d",
+		langEN: "%d–%d of %d",
+		langUK: "%d–%d з %d",
+	},
+	"tui.help": {
+		langRU: "q выход · s/S сортировка · e биржа · / фильтр · Esc сброс · ↑↓ PgUp PgDn · u обновить",
+		langEN: "q quit · s/S sort · e exchange · / filter · Esc reset · ↑↓ PgUp PgDn · u refresh",
+		langUK: "q вихід · s/S сортування · e біржа · / фільтр · Esc скидання · ↑↓ PgUp PgDn · u оновити",
+	},
+	"tui.filter_prompt": {
+		langRU: "Фильтр по тикеру или бирже (Enter — готово, Esc — сбросить): %s",
+		langEN: "Filter by symbol or exchange (Enter to apply, Esc to clear): %s",
+		langUK: "Фільтр за тікером або біржею (Enter — готово, Esc — скинути): %s",
+	},
+
+	// Веб-панель
+	"web.title": {
+		langRU: "Фандинг-скринер",
+		langEN: "Funding screener",
+		langUK: "Фандинг-скринер",
+	},
+	"web.tab.rates": {
+		langRU: "Ставки",
+		langEN: "Rates",
+		langUK: "Ставки",
+	},
+	"web.tab.spreads": {
+		langRU: "Спреды",
+		langEN: "Spreads",
+		langUK: "Спреди",
+	},
+	"web.tab.history": {
+		langRU: "История",
+		langEN: "History",
+		langUK: "Історія",
+	},
+	"web.tab.health": {
+		langRU: "Биржи",
+		langEN: "Exchanges",
+		langUK: "Біржі",
+	},
+	"web.filter": {
+		langRU: "Тикер или биржа",
+		langEN: "Symbol or exchange",
+		langUK: "Тікер або біржа",
+	},
+	"web.all_exchanges": {
+		langRU: "Все биржи",
+		langEN: "All exchanges",
+		langUK: "Усі біржі",
+	},
+	"web.live": {
+		langRU: "обновляется онлайн",
+		langEN: "live",
+		langUK: "оновлюється онлайн",
+	},
+	"web.offline": {
+		langRU: "нет связи с сервером",
+		langEN: "no connection to the server",
+		langUK: "немає зв'язку з сервером",
+	},
+	"web.updated": {
+		langRU: "обновлено в %s",
+		langEN: "updated at %s",
+		langUK: "оновлено о %s",
+	},
+	"web.shown": {
+		langRU: "%d из %d",
+		langEN: "%d of %d",
+		langUK: "%d з %d",
+	},
+	"web.empty": {
+		langRU: "Нет данных",
+		langEN: "No data",
+		langUK: "Немає даних",
+	},
+	"web.spread.min": {
+		langRU: "Мин. спред, %",
+		langEN: "Min spread, %",
+		langUK: "Мін. спред, %",
+	},
+	"web.col.base": {
+		langRU: "Монета",
+		langEN: "Coin",
+		langUK: "Монета",
+	},
+	"web.col.long": {
+		langRU: "Long",
+		langEN: "Long",
+		langUK: "Long",
+	},
+	"web.col.short": {
+		langRU: "Short",
+		langEN: "Short",
+		langUK: "Short",
+	},
+	"web.col.spread": {
+		langRU: "Спред 8ч",
+		langEN: "Spread 8h",
+		langUK: "Спред 8год",
+	},
+	"web.col.venues": {
+		langRU: "Бирж",
+		langEN: "Venues",
+		langUK: "Бірж",
+	},
+	"web.col.state": {
+		langRU: "Состояние",
+		langEN: "State",
+		langUK: "Стан",
+	},
+	"web.col.updated": {
+		langRU: "Обновлена",
+		langEN: "Updated",
+		langUK: "Оновлена",
+	},
+	"web.col.error": {
+		langRU: "Ошибка",
+		langEN: "Error",
+		langUK: "Помилка",
+	},
+	"web.history.symbol": {
+		langRU: "Монета, например BTC",
+		langEN: "Coin, e.g. BTC",
+		langUK: "Монета, наприклад BTC",
+	},
+	"web.history.exchanges": {
+		langRU: "Биржи через запятую",
+		langEN: "Exchanges, comma-separated",
+		langUK: "Біржі через кому",
+	},
+	"web.history.show": {
+		langRU: "Показать",
+		langEN: "Show",
+		langUK: "Показати",
+	},
+	"web.history.hint": {
+		langRU: "Нажмите на тикер в таблице ставок или спредов, чтобы открыть его историю.",
+		langEN: "Click a symbol in the rates or spreads table to open its history.",
+		langUK: "Натисніть на тікер у таблиці ставок або спредів, щоб відкрити його історію.",
+	},
+	"web.health.ok": {
+		langRU: "работает",
+		langEN: "ok",
+		langUK: "працює",
+	},
+	"web.health.stale": {
+		langRU: "данные устарели",
+		langEN: "stale",
+		langUK: "дані застаріли",
+	},
+	"web.health.error": {
+		langRU: "ошибка",
+		langEN: "error",
+		langUK: "помилка",
+	},
+	"web.health.pending": {
+		langRU: "ещё не обновлялась",
+		langEN: "not updated yet",
+		langUK: "ще не оновлювалася",
+	},
+	"web.health.ago": {
+		langRU: "%s назад",
+		langEN: "%s ago",
+		langUK: "%s тому",
+	},
+	"web.chart.no_symbol": {
+		langRU: "Укажите монету, например BTC.",
+		langEN: "Specify a coin, e.g. BTC.",
+		langUK: "Вкажіть монету, наприклад BTC.",
+	},
+	"web.chart.no_history": {
+		langRU: "Этот процесс не записывает историю ставок.",
+		langEN: "Rate history is not recorded by this process.",
+		langUK: "Цей процес не записує історію ставок.",
+	},
+
+	"message.as_document": {
+		langRU: "Сообщение слишком длинное, отправляю файлом",
+		langEN: "The message is too long, sending it as a file",
+		langUK: "Повідомлення надто довге, надсилаю файлом",
+	},
+
+	// Рассылка во внешние каналы: Discord, Slack
+	"notify.title": {
+		langRU: "📈 Ставки фандинга",
+		langEN: "📈 Funding rates",
+		langUK: "📈 Ставки фандингу",
+	},
+	"notify.digest_title": {
+		langRU: "📰 Дайджест ставок фандинга",
+		langEN: "📰 Funding rate digest",
+		langUK: "📰 Дайджест ставок фандингу",
+	},
+	"notify.none": {
+		langRU: "Нет ставок фандинга, превышающих порог",
+		langEN: "No funding rates above the threshold",
+		langUK: "Немає ставок фандингу, що перевищують поріг",
+	},
+	"notify.footer": {
+		langRU: "Порог %s",
+		langEN: "Threshold %s",
+		langUK: "Поріг %s",
+	},
+	"notify.more": {
+		langRU: "… и ещё %d запись|… и ещё %d записи|… и ещё %d записей",
+		langEN: "… and %d more entry|… and %d more entries",
+		langUK: "… і ще %d запис|… і ще %d записи|… і ще %d записів",
+	},
+
+	// /minvolume
+	"minvolume.off": {
+		langRU: "Фильтр по объёму выключен.",
+		langEN: "The volume filter is off.",
+		langUK: "Фільтр за обсягом вимкнено.",
+	},
+	"minvolume.current": {
+		langRU: "Минимальный суточный объём: %s",
+		langEN: "Minimum daily volume: %s",
+		langUK: "Мінімальний добовий обсяг: %s",
+	},
+	"minvolume.usage": {
+		langRU: "\nИспользование: /minvolume 5M, отключить: /minvolume off",
+		langEN: "\nUsage: /minvolume 5M, turn off: /minvolume off",
+		langUK: "\nВикористання: /minvolume 5M, вимкнути: /minvolume off",
+	},
+	"minvolume.admin_only": {
+		langRU: "Фильтр по объёму в группе могут менять только администраторы.",
+		langEN: "Only administrators can change the volume filter in a group.",
+		langUK: "Фільтр за обсягом у групі можуть змінювати лише адміністратори.",
+	},
+	"minvolume.error": {
+		langRU: "Ошибка: укажите объём, например: /minvolume 5M",
+		langEN: "Error: specify a volume, e.g. /minvolume 5M",
+		langUK: "Помилка: вкажіть обсяг, наприклад: /minvolume 5M",
+	},
+	"minvolume.set": {
+		langRU: "Установлен минимальный суточный объём: %s. Ставки с меньшим или неизвестным объёмом скрываются.",
+		langEN: "Minimum daily volume set to %s. Rates with a lower or unknown volume are hidden.",
+		langUK: "Встановлено мінімальний добовий обсяг: %s. Ставки з меншим або невідомим обсягом приховуються.",
+	},
+
+	// Расписание
+	"schedule.current": {
+		langRU: "Расписание рассылки: %s",
+		langEN: "Notification schedule: %s",
+		langUK: "Розклад розсилки: %s",
+	},
+	"schedule.digest": {
+		langRU: "дайджест в %s (%s)",
+		langEN: "digest at %s (%s)",
+		langUK: "дайджест о %s (%s)",
+	},
+	"schedule.every": {
+		langRU: "каждые %s",
+		langEN: "every %s",
+		langUK: "кожні %s",
+	},
+	"schedule.quiet": {
+		langRU: ", тихие часы %s–%s (%s)",
+		langEN: ", quiet hours %s–%s (%s)",
+		langUK: ", тихі години %s–%s (%s)",
+	},
+	"schedule.admin_only": {
+		langRU: "Расписание в группе могут менять только администраторы.",
+		langEN: "Only administrators can change the schedule in a group.",
+		langUK: "Розклад у групі можуть змінювати лише адміністратори.",
+	},
+	"every.usage": {
+		langRU: "\nИспользование: /every 30m, вернуть период по умолчанию: /every default",
+		langEN: "\nUsage: /every 30m, restore the default interval: /every default",
+		langUK: "\nВикористання: /every 30m, повернути період за замовчуванням: /every default",
+	},
+	"every.error": {
+		langRU: "Ошибка: укажите период не меньше %s, например: /every 30m",
+		langEN: "Error: specify an interval of at least %s, e.g. /every 30m",
+		langUK: "Помилка: вкажіть період не менше %s, наприклад: /every 30m",
+	},
+	"quiet.usage": {
+		langRU: "\nИспользование: /quiet 23:00-08:00 [Europe/Kyiv], отключить: /quiet off",
+		langEN: "\nUsage: /quiet 23:00-08:00 [Europe/Kyiv], turn off: /quiet off",
+		langUK: "\nВикористання: /quiet 23:00-08:00 [Europe/Kyiv], вимкнути: /quiet off",
+	},
+	"quiet.off": {
+		langRU: "Тихие часы отключены. Расписание рассылки: %s",
+		langEN: "Quiet hours are off. Notification schedule: %s",
+		langUK: "Тихі години вимкнено. Розклад розсилки: %s",
+	},
+	"quiet.error": {
+		langRU: "Ошибка: укажите интервал, например: /quiet 23:00-08:00 Europe/Kyiv",
+		langEN: "Error: specify a range, e.g. /quiet 23:00-08:00 Europe/Kyiv",
+		langUK: "Помилка: вкажіть інтервал, наприклад: /quiet 23:00-08:00 Europe/Kyiv",
+	},
+	"quiet.same": {
+		langRU: "Ошибка: начало и конец тихих часов совпадают",
+		langEN: "Error: quiet hours start and end at the same time",
+		langUK: "Помилка: початок і кінець тихих годин збігаються",
+	},
+	"digest.usage": {
+		langRU: "\nИспользование: /digest 09:00 18:00 — одна сводка в указанное местное время, отключить: /digest off",
+		langEN: "\nUsage: /digest 09:00 18:00 — one summary at each local time, turn off: /digest off",
+		langUK: "\nВикористання: /digest 09:00 18:00 — одне зведення у вказаний місцевий час, вимкнути: /digest off",
+	},
+	"live.header": {
+		langRU: "<b>📡 Ставки фандинга онлайн</b>",
+		langEN: "<b>📡 Live funding rates</b>",
+		langUK: "<b>📡 Ставки фандингу онлайн</b>",
+	},
+	"live.updated": {
+		langRU: "<i>Обновлено: %s</i>",
+		langEN: "<i>Updated: %s</i>",
+		langUK: "<i>Оновлено: %s</i>",
+	},
+	"live.on": {
+		langRU: "Табло закреплено и обновляется каждые %s. Пока оно включено, плановая рассылка в этот чат не приходит. Выключить: /live off",
+		langEN: "The board is pinned and updates every %s. While it is on, scheduled broadcasts to this chat are paused. Turn off: /live off",
+		langUK: "Табло закріплено й оновлюється кожні %s. Поки воно ввімкнене, планова розсилка в цей чат не надходить. Вимкнути: /live off",
+	},
+	"live.off": {
+		langRU: "Табло выключено, плановая рассылка снова приходит по расписанию чата.",
+		langEN: "The board is off, scheduled broadcasts resume on the chat's schedule.",
+		langUK: "Табло вимкнено, планова розсилка знову надходить за розкладом чату.",
+	},
+	"live.not_on": {
+		langRU: "Табло в этом чате не включено. Включить: /live",
+		langEN: "The board is not on in this chat. Turn on: /live",
+		langUK: "Табло в цьому чаті не ввімкнено. Увімкнути: /live",
+	},
+	"live.usage": {
+		langRU: "Использование: /live — включить табло, /live off — выключить",
+		langEN: "Usage: /live — turn the board on, /live off — turn it off",
+		langUK: "Використання: /live — увімкнути табло, /live off — вимкнути",
+	},
+	"live.error": {
+		langRU: "Не удалось опубликовать табло, попробуйте позже.",
+		langEN: "Could not post the board, please try again later.",
+		langUK: "Не вдалося опублікувати табло, спробуйте пізніше.",
+	},
+	"live.admin_only": {
+		langRU: "Табло в группе могут включать только администраторы.",
+		langEN: "Only administrators can manage the board in a group.",
+		langUK: "Табло в групі можуть вмикати лише адміністратори.",
+	},
+	"err.bad_clock": {
+		langRU: "некорректное время %q, ожидается ЧЧ:ММ",
+		langEN: "invalid time %q, expected HH:MM",
+		langUK: "некоректний час %q, очікується ГГ:ХХ",
+	},
+	"err.bad_format": {
+		langRU: "укажите формат выгрузки: csv или json",
+		langEN: "specify the export format: csv or json",
+		langUK: "вкажіть формат вивантаження: csv або json",
+	},
+	"err.bad_period": {
+		langRU: "некорректный период %q, примеры: 24h, 7d, 2w",
+		langEN: "invalid period %q, e.g. 24h, 7d, 2w",
+		langUK: "некоректний період %q, приклади: 24h, 7d, 2w",
+	},
+
+	// Часовой пояс и время
+	"tz.current": {
+		langRU: "Часовой пояс: %s (сейчас %s)\nИспользование: /tz Europe/Kyiv или /tz UTC+3, сбросить: /tz default",
+		langEN: "Time zone: %s (now %s)\nUsage: /tz Europe/Kyiv or /tz UTC+3, reset: /tz default",
+		langUK: "Часовий пояс: %s (зараз %s)\nВикористання: /tz Europe/Kyiv або /tz UTC+3, скинути: /tz default",
+	},
+	"tz.admin_only": {
+		langRU: "Часовой пояс в группе могут менять только администраторы.",
+		langEN: "Only administrators can change the time zone in a group.",
+		langUK: "Часовий пояс у групі можуть змінювати лише адміністратори.",
+	},
+	"tz.unknown": {
+		langRU: "Ошибка: неизвестный часовой пояс %s. Пример: /tz Europe/Kyiv или /tz UTC+3",
+		langEN: "Error: unknown time zone %s. Example: /tz Europe/Kyiv or /tz UTC+3",
+		langUK: "Помилка: невідомий часовий пояс %s. Приклад: /tz Europe/Kyiv або /tz UTC+3",
+	},
+	"tz.set": {
+		langRU: "Часовой пояс: %s (сейчас %s). Время выплат, расписание рассылки и тихие часы показываются в нём.",
+		langEN: "Time zone: %s (now %s). Payment times, the notification schedule and quiet hours use it.",
+		langUK: "Часовий пояс: %s (зараз %s). Час виплат, розклад розсилки й тихі години показуються в ньому.",
+	},
+	"countdown.in": {
+		langRU: "через %s",
+		langEN: "in %s",
+		langUK: "через %s",
+	},
+	"unit.day": {
+		langRU: "д",
+		langEN: "d",
+		langUK: "д",
+	},
+	"unit.hour": {
+		langRU: "ч",
+		langEN: "h",
+		langUK: "год",
+	},
+	"unit.minute": {
+		langRU: "м",
+		langEN: "m",
+		langUK: "хв",
+	},
+	"ago": {
+		langRU: "%s (%v назад)",
+		langEN: "%s (%v ago)",
+		langUK: "%s (%v тому)",
+	},
+
+	// /lang
+	"lang.current": {
+		langRU: "Язык: %s\nДоступные языки:\n%s\nИспользование: /lang en, определять по настройкам Telegram: /lang auto",
+		langEN: "Language: %s\nAvailable languages:\n%s\nUsage: /lang ru, follow Telegram settings: /lang auto",
+		langUK: "Мова: %s\nДоступні мови:\n%s\nВикористання: /lang en, визначати за налаштуваннями Telegram: /lang auto",
+	},
+	"lang.admin_only": {
+		langRU: "Язык в группе могут менять только администраторы.",
+		langEN: "Only administrators can change the language in a group.",
+		langUK: "Мову в групі можуть змінювати лише адміністратори.",
+	},
+	"lang.unknown": {
+		langRU: "Неизвестный язык %s. Доступны: ru, en, uk",
+		langEN: "Unknown language %s. Available: ru, en, uk",
+		langUK: "Невідома мова %s. Доступні: ru, en, uk",
+	},
+	"lang.set": {
+		langRU: "Язык бота: %s",
+		langEN: "Bot language: %s",
+		langUK: "Мова бота: %s",
+	},
+
+	// Закрытый режим
+	"invite.error": {
+		langRU: "Ошибка: укажите срок действия, например: /invite 12h",
+		langEN: "Error: specify a lifetime, e.g. /invite 12h",
+		langUK: "Помилка: вкажіть термін дії, наприклад: /invite 12h",
+	},
+	"invite.failed": {
+		langRU: "Не удалось создать код приглашения",
+		langEN: "Failed to create an invite code",
+		langUK: "Не вдалося створити код запрошення",
+	},
+	"invite.created": {
+		langRU: "Код приглашения: <code>%s</code>\nДействует до %s\nСсылка: https://t.me/%s?start=%s",
+		langEN: "Invite code: <code>%s</code>\nValid until %s\nLink: https://t.me/%s?start=%s",
+		langUK: "Код запрошення: <code>%s</code>\nДіє до %s\nПосилання: https://t.me/%s?start=%s",
+	},
+	"access.bad_code": {
+		langRU: "Код приглашения недействителен или истёк. Попросите у администратора новый.",
+		langEN: "The invite code is invalid or expired. Ask an administrator for a new one.",
+		langUK: "Код запрошення недійсний або прострочений. Попросіть в адміністратора новий.",
+	},
+	"access.granted": {
+		langRU: "Доступ открыт, добро пожаловать!",
+		langEN: "Access granted, welcome!",
+		langUK: "Доступ відкрито, ласкаво просимо!",
+	},
+	"access.denied": {
+		langRU: "Извините, бот работает в закрытом режиме.\nЕсли у вас есть код приглашения, отправьте /redeem КОД",
+		langEN: "Sorry, the bot is running in private mode.\nIf you have an invite code, send /redeem CODE",
+		langUK: "Вибачте, бот працює в закритому режимі.\nЯкщо у вас є код запрошення, надішліть /redeem КОД",
+	},
+
+	// Команды администратора
+	"admin.paused": {
+		langRU: "Рассылка приостановлена. Для возобновления используйте /resume",
+		langEN: "Notifications paused. Use /resume to continue",
+		langUK: "Розсилку призупинено. Для відновлення використовуйте /resume",
+	},
+	"admin.resumed": {
+		langRU: "Рассылка возобновлена.",
+		langEN: "Notifications resumed.",
+		langUK: "Розсилку відновлено.",
+	},
+	"status.title": {
+		langRU: "<b>🛠 Статус бота</b>\n",
+		langEN: "<b>🛠 Bot status</b>\n",
+		langUK: "<b>🛠 Статус бота</b>\n",
+	},
+	"status.not_updated": {
+		langRU: "%-12s ещё не обновлялась",
+		langEN: "%-12s not updated yet",
+		langUK: "%-12s ще не оновлювалася",
+	},
+	"status.never": {
+		langRU: "никогда",
+		langEN: "never",
+		langUK: "ніколи",
+	},
+	"status.line": {
+		langRU: "%-12s %5d ставка  обновлено: %s|%-12s %5d ставки  обновлено: %s|%-12s %5d ставок  обновлено: %s",
+		langEN: "%-12s %5d rate   updated: %s|%-12s %5d rates  updated: %s",
+		langUK: "%-12s %5d ставка  оновлено: %s|%-12s %5d ставки  оновлено: %s|%-12s %5d ставок  оновлено: %s",
+	},
+	"status.error": {
+		langRU: "\n  ошибка: %s",
+		langEN: "\n  error: %s",
+		langUK: "\n  помилка: %s",
+	},
+	"status.active": {
+		langRU: "активна",
+		langEN: "active",
+		langUK: "активна",
+	},
+	"status.paused": {
+		langRU: "приостановлена",
+		langEN: "paused",
+		langUK: "призупинена",
+	},
+	"status.no_broadcast": {
+		langRU: "ещё не было",
+		langEN: "none yet",
+		langUK: "ще не було",
+	},
+	"status.rabbit": {
+		langRU: "Очередь RabbitMQ: %d/%d\n",
+		langEN: "RabbitMQ queue: %d/%d\n",
+		langUK: "Черга RabbitMQ: %d/%d\n",
+	},
+	"status.retry": {
+		langRU: "Ожидают повторной отправки: %d\n",
+		langEN: "Waiting for retry: %d\n",
+		langUK: "Очікують повторного надсилання: %d\n",
+	},
+	"status.subscribers": {
+		langRU: "Подписчиков: %d\n",
+		langEN: "Subscribers: %d\n",
+		langUK: "Підписників: %d\n",
+	},
+	"status.broadcast": {
+		langRU: "Рассылка: %s (последняя: %s)",
+		langEN: "Notifications: %s (last: %s)",
+		langUK: "Розсилка: %s (остання: %s)",
+	},
+	"status.dispatcher_queue": {
+		langRU: "Исходящая очередь: ответы %d, рассылки %d, в отправке %d\n",
+		langEN: "Outgoing queue: replies %d, notifications %d, in flight %d\n",
+		langUK: "Вихідна черга: відповіді %d, розсилки %d, надсилаються %d\n",
+	},
+	"status.dispatcher_sent": {
+		langRU: "Отправлено: %d, ошибок: %d (429: %d), ожидание: сред. %v, макс. %v\n",
+		langEN: "Sent: %d, errors: %d (429: %d), wait: avg %v, max %v\n",
+		langUK: "Надіслано: %d, помилок: %d (429: %d), очікування: сер. %v, макс. %v\n",
+	},
+	"broadcast.usage": {
+		langRU: "Использование: /broadcast текст сообщения",
+		langEN: "Usage: /broadcast message text",
+		langUK: "Використання: /broadcast текст повідомлення",
+	},
+	"broadcast.sent": {
+		langRU: "Сообщение отправлено %d подписчику|Сообщение отправлено %d подписчикам|Сообщение отправлено %d подписчикам",
+		langEN: "Message sent to %d subscriber|Message sent to %d subscribers",
+		langUK: "Повідомлення надіслано %d підписнику|Повідомлення надіслано %d підписникам|Повідомлення надіслано %d підписникам",
+	},
+	"users.title": {
+		langRU: "<b>👥 Подписчиков: %d</b>\nПорог по умолчанию: %.3f%%",
+		langEN: "<b>👥 Subscribers: %d</b>\nDefault threshold: %.3f%%",
+		langUK: "<b>👥 Підписників: %d</b>\nПоріг за замовчуванням: %.3f%%",
+	},
+	"reload.done": {
+		langRU: "Настройки перечитаны. Подписчиков: %d, получателей во внешних каналах: %d, администраторов: %d",
+		langEN: "Settings reloaded. Subscribers: %d, external targets: %d, administrators: %d",
+		langUK: "Налаштування перечитано. Підписників: %d, отримувачів у зовнішніх каналах: %d, адміністраторів: %d",
+	},
+	"force_update.started": {
+		langRU: "Запускаю обновление ставок...",
+		langEN: "Updating rates...",
+		langUK: "Запускаю оновлення ставок...",
+	},
+	"force_update.done": {
+		langRU: "Обновление завершено за %v",
+		langEN: "Update finished in %v",
+		langUK: "Оновлення завершено за %v",
+	},
+
+	// Описания команд для меню и /help
+	"cmd.start": {
+		langRU: "Информация о боте",
+		langEN: "About the bot",
+		langUK: "Інформація про бота",
+	},
+	"cmd.start.usage": {
+		langRU: "Примеры:\n" +
+			"/start",
+		langEN: "Examples:\n" +
+			"/start",
+		langUK: "Приклади:\n" +
+			"/start",
+	},
+	"cmd.help": {
+		langRU: "Справка по командам",
+		langEN: "Command help",
+		langUK: "Довідка з команд",
+	},
+	"cmd.help.usage": {
+		langRU: "Примеры:\n" +
+			"/help — список команд\n" +
+			"/help top — описание и примеры команды",
+		langEN: "Examples:\n" +
+			"/help — list commands\n" +
+			"/help top — description and examples for a command",
+		langUK: "Приклади:\n" +
+			"/help — список команд\n" +
+			"/help top — опис і приклади команди",
+	},
+	"cmd.rates": {
+		langRU: "Текущие ставки фандинга",
+		langEN: "Current funding rates",
+		langUK: "Поточні ставки фандингу",
+	},
+	"cmd.rates.usage": {
+		langRU: "Примеры:\n" +
+			"/rates — ставки выше порога чата постранично; кнопками переключаются биржи, сортировка и страницы",
+		langEN: "Examples:\n" +
+			"/rates — rates above the chat threshold, paginated; buttons switch exchanges, sorting and pages",
+		langUK: "Приклади:\n" +
+			"/rates — ставки вище порогу чату посторінково; кнопками перемикаються біржі, сортування та сторінки",
+	},
+	"cmd.symbol": {
+		langRU: "Ставки монеты на всех биржах",
+		langEN: "One coin's rates on every exchange",
+		langUK: "Ставки монети на всіх біржах",
+	},
+	"cmd.symbol.usage": {
+		langRU: "Примеры:\n" +
+			"/symbol BTC\n" +
+			"/symbol ETHUSDT\n" +
+			"/symbol BTC-USDT-SWAP — подходит любое написание тикера",
+		langEN: "Examples:\n" +
+			"/symbol BTC\n" +
+			"/symbol ETHUSDT\n" +
+			"/symbol BTC-USDT-SWAP — any ticker spelling works",
+		langUK: "Приклади:\n" +
+			"/symbol BTC\n" +
+			"/symbol ETHUSDT\n" +
+			"/symbol BTC-USDT-SWAP — підходить будь-яке написання тікера",
+	},
+	"cmd.top": {
+		langRU: "Самые экстремальные ставки по всем биржам",
+		langEN: "The most extreme rates across exchanges",
+		langUK: "Найекстремальніші ставки на всіх біржах",
+	},
+	"cmd.top.usage": {
+		langRU: "Примеры:\n" +
+			"/top — 10 ставок с наибольшим модулем\n" +
+			"/top 20 negative — 20 самых отрицательных\n" +
+			"/top positive minvol=5M exchange=binance,bybit",
+		langEN: "Examples:\n" +
+			"/top — 10 rates with the largest absolute value\n" +
+			"/top 20 negative — the 20 most negative\n" +
+			"/top positive minvol=5M exchange=binance,bybit",
+		langUK: "Приклади:\n" +
+			"/top — 10 ставок з найбільшим модулем\n" +
+			"/top 20 negative — 20 найвід'ємніших\n" +
+			"/top positive minvol=5M exchange=binance,bybit",
+	},
+	"cmd.chart": {
+		langRU: "График истории ставок монеты",
+		langEN: "Funding history chart for a coin",
+		langUK: "Графік історії ставок монети",
+	},
+	"cmd.chart.usage": {
+		langRU: "Примеры:\n" +
+			"/chart BTC — за 7 дней на всех биржах\n" +
+			"/chart ETH 24h\n" +
+			"/chart BTC 14d binance,bybit — только выбранные биржи",
+		langEN: "Examples:\n" +
+			"/chart BTC — last 7 days on every exchange\n" +
+			"/chart ETH 24h\n" +
+			"/chart BTC 14d binance,bybit — selected exchanges only",
+		langUK: "Приклади:\n" +
+			"/chart BTC — за 7 днів на всіх біржах\n" +
+			"/chart ETH 24h\n" +
+			"/chart BTC 14d binance,bybit — лише обрані біржі",
+	},
+	"cmd.export": {
+		langRU: "Выгрузка ставок в CSV или JSON",
+		langEN: "Export rates as CSV or JSON",
+		langUK: "Вивантаження ставок у CSV або JSON",
+	},
+	"cmd.export.usage": {
+		langRU: "Примеры:\n" +
+			"/export csv — текущие ставки всех бирж\n" +
+			"/export json binance — текущие ставки Binance\n" +
+			"/export csv bybit,okx 7d — история за 7 дней",
+		langEN: "Examples:\n" +
+			"/export csv — current rates on every exchange\n" +
+			"/export json binance — current Binance rates\n" +
+			"/export csv bybit,okx 7d — history for 7 days",
+		langUK: "Приклади:\n" +
+			"/export csv — поточні ставки всіх бірж\n" +
+			"/export json binance — поточні ставки Binance\n" +
+			"/export csv bybit,okx 7d — історія за 7 днів",
+	},
+	"cmd.subscribe": {
+		langRU: "Подписаться на уведомления",
+		langEN: "Subscribe to notifications",
+		langUK: "Підписатися на сповіщення",
+	},
+	"cmd.subscribe.usage": {
+		langRU: "Примеры:\n" +
+			"/subscribe — в группе с темами рассылка пойдёт в тему, где отправлена команда",
+		langEN: "Examples:\n" +
+			"/subscribe — in a group with topics notifications go to the topic the command was sent from",
+		langUK: "Приклади:\n" +
+			"/subscribe — у групі з темами розсилка йтиме в тему, де надіслано команду",
+	},
+	"cmd.unsubscribe": {
+		langRU: "Отписаться от уведомлений",
+		langEN: "Unsubscribe from notifications",
+		langUK: "Відписатися від сповіщень",
+	},
+	"cmd.unsubscribe.usage": {
+		langRU: "Примеры:\n" +
+			"/unsubscribe",
+		langEN: "Examples:\n" +
+			"/unsubscribe",
+		langUK: "Приклади:\n" +
+			"/unsubscribe",
+	},
+	"cmd.threshold": {
+		langRU: "Порог ставки для уведомлений",
+		langEN: "Rate threshold for notifications",
+		langUK: "Поріг ставки для сповіщень",
+	},
+	"cmd.threshold.usage": {
+		langRU: "Примеры:\n" +
+			"/threshold — показать текущий порог\n" +
+			"/threshold 0.1 — один порог для обоих направлений, в процентах\n" +
+			"/threshold +0.05 -0.2 — отдельные пороги: +0.05% и −0.2%\n" +
+			"/threshold negative — только отрицательные ставки\n" +
+			"/threshold both — оба направления\n\n" +
+			"Значения всегда в процентах: 0.1 — это 0.1%, а не 10%",
+		langEN: "Examples:\n" +
+			"/threshold — show the current threshold\n" +
+			"/threshold 0.1 — one threshold for both directions, in percent\n" +
+			"/threshold +0.05 -0.2 — separate thresholds: +0.05% and −0.2%\n" +
+			"/threshold negative — negative rates only\n" +
+			"/threshold both — both directions\n\n" +
+			"Values are always in percent: 0.1 means 0.1%, not 10%",
+		langUK: "Приклади:\n" +
+			"/threshold — показати поточний поріг\n" +
+			"/threshold 0.1 — один поріг для обох напрямків, у відсотках\n" +
+			"/threshold +0.05 -0.2 — окремі пороги: +0.05% і −0.2%\n" +
+			"/threshold negative — лише від'ємні ставки\n" +
+			"/threshold both — обидва напрямки\n\n" +
+			"Значення завжди у відсотках: 0.1 — це 0.1%, а не 10%",
+	},
+	"cmd.minvolume": {
+		langRU: "Минимальный суточный объём",
+		langEN: "Minimum daily volume",
+		langUK: "Мінімальний добовий обсяг",
+	},
+	"cmd.minvolume.usage": {
+		langRU: "Примеры:\n" +
+			"/minvolume 5M — скрывать ставки с объёмом меньше $5M\n" +
+			"/minvolume off — выключить фильтр",
+		langEN: "Examples:\n" +
+			"/minvolume 5M — hide rates with a volume below $5M\n" +
+			"/minvolume off — turn the filter off",
+		langUK: "Приклади:\n" +
+			"/minvolume 5M — приховувати ставки з обсягом менше $5M\n" +
+			"/minvolume off — вимкнути фільтр",
+	},
+	"cmd.every": {
+		langRU: "Период рассылки",
+		langEN: "Notification interval",
+		langUK: "Період розсилки",
+	},
+	"cmd.every.usage": {
+		langRU: "Примеры:\n" +
+			"/every 30m\n" +
+			"/every 2h\n" +
+			"/every default — 5 минут",
+		langEN: "Examples:\n" +
+			"/every 30m\n" +
+			"/every 2h\n" +
+			"/every default — 5 minutes",
+		langUK: "Приклади:\n" +
+			"/every 30m\n" +
+			"/every 2h\n" +
+			"/every default — 5 хвилин",
+	},
+	"cmd.quiet": {
+		langRU: "Тихие часы без рассылки",
+		langEN: "Quiet hours without notifications",
+		langUK: "Тихі години без розсилки",
+	},
+	"cmd.quiet.usage": {
+		langRU: "Примеры:\n" +
+			"/quiet 23:00-08:00\n" +
+			"/quiet 23:00-08:00 Europe/Kyiv — заодно задать часовой пояс\n" +
+			"/quiet off",
+		langEN: "Examples:\n" +
+			"/quiet 23:00-08:00\n" +
+			"/quiet 23:00-08:00 Europe/Kyiv — also sets the time zone\n" +
+			"/quiet off",
+		langUK: "Приклади:\n" +
+			"/quiet 23:00-08:00\n" +
+			"/quiet 23:00-08:00 Europe/Kyiv — заразом задати часовий пояс\n" +
+			"/quiet off",
+	},
+	"cmd.digest": {
+		langRU: "Сводка в заданное время",
+		langEN: "Summary at fixed times",
+		langUK: "Зведення у заданий час",
+	},
+	"cmd.digest.usage": {
+		langRU: "Примеры:\n" +
+			"/digest 09:00 — одна сводка в 9 утра\n" +
+			"/digest 09:00 18:00\n" +
+			"/digest off — вернуться к периодической рассылке",
+		langEN: "Examples:\n" +
+			"/digest 09:00 — one summary at 9 am\n" +
+			"/digest 09:00 18:00\n" +
+			"/digest off — back to periodic notifications",
+		langUK: "Приклади:\n" +
+			"/digest 09:00 — одне зведення о 9 ранку\n" +
+			"/digest 09:00 18:00\n" +
+			"/digest off — повернутися до періодичної розсилки",
+	},
+	"cmd.live": {
+		langRU: "Живое табло ставок",
+		langEN: "Live rate board",
+		langUK: "Живе табло ставок",
+	},
+	"cmd.live.usage": {
+		langRU: "Примеры:\n" +
+			"/live — закрепить табло, которое обновляется само\n" +
+			"/live off — выключить табло",
+		langEN: "Examples:\n" +
+			"/live — pin a board that updates itself\n" +
+			"/live off — turn the board off",
+		langUK: "Приклади:\n" +
+			"/live — закріпити табло, яке оновлюється саме\n" +
+			"/live off — вимкнути табло",
+	},
+	"cmd.tz": {
+		langRU: "Часовой пояс чата",
+		langEN: "Chat time zone",
+		langUK: "Часовий пояс чату",
+	},
+	"cmd.tz.usage": {
+		langRU: "Примеры:\n" +
+			"/tz Europe/Kyiv\n" +
+			"/tz UTC+3\n" +
+			"/tz default — пояс из настроек бота",
+		langEN: "Examples:\n" +
+			"/tz Europe/Kyiv\n" +
+			"/tz UTC+3\n" +
+			"/tz default — the bot's default zone",
+		langUK: "Приклади:\n" +
+			"/tz Europe/Kyiv\n" +
+			"/tz UTC+3\n" +
+			"/tz default — пояс із налаштувань бота",
+	},
+	"cmd.lang": {
+		langRU: "Язык бота",
+		langEN: "Bot language",
+		langUK: "Мова бота",
+	},
+	"cmd.lang.usage": {
+		langRU: "Примеры:\n" +
+			"/lang en\n" +
+			"/lang uk\n" +
+			"/lang auto — по языку Telegram",
+		langEN: "Examples:\n" +
+			"/lang ru\n" +
+			"/lang uk\n" +
+			"/lang auto — follow the Telegram language",
+		langUK: "Приклади:\n" +
+			"/lang en\n" +
+			"/lang ru\n" +
+			"/lang auto — за мовою Telegram",
+	},
+	"cmd.status": {
+		langRU: "Состояние бирж и очередей",
+		langEN: "Exchange and queue status",
+		langUK: "Стан бірж і черг",
+	},
+	"cmd.status.usage": {
+		langRU: "Примеры:\n" +
+			"/status",
+		langEN: "Examples:\n" +
+			"/status",
+		langUK: "Приклади:\n" +
+			"/status",
+	},
+	"cmd.broadcast": {
+		langRU: "Сообщение всем подписчикам",
+		langEN: "Message all subscribers",
+		langUK: "Повідомлення всім підписникам",
+	},
+	"cmd.broadcast.usage": {
+		langRU: "Примеры:\n" +
+			"/broadcast <b>Плановые работы</b> в 03:00 UTC — поддерживается HTML-разметка",
+		langEN: "Examples:\n" +
+			"/broadcast <b>Maintenance</b> at 03:00 UTC — HTML markup is supported",
+		langUK: "Приклади:\n" +
+			"/broadcast <b>Планові роботи</b> о 03:00 UTC — підтримується HTML-розмітка",
+	},
+	"cmd.users": {
+		langRU: "Подписчики и их пороги",
+		langEN: "Subscribers and their thresholds",
+		langUK: "Підписники та їхні пороги",
+	},
+	"cmd.users.usage": {
+		langRU: "Примеры:\n" +
+			"/users",
+		langEN: "Examples:\n" +
+			"/users",
+		langUK: "Приклади:\n" +
+			"/users",
+	},
+	"cmd.reload": {
+		langRU: "Перечитать .env и настройки",
+		langEN: "Reload .env and settings",
+		langUK: "Перечитати .env і налаштування",
+	},
+	"cmd.reload.usage": {
+		langRU: "Примеры:\n" +
+			"/reload",
+		langEN: "Examples:\n" +
+			"/reload",
+		langUK: "Приклади:\n" +
+			"/reload",
+	},
+	"cmd.pause": {
+		langRU: "Приостановить рассылку",
+		langEN: "Pause notifications",
+		langUK: "Призупинити розсилку",
+	},
+	"cmd.pause.usage": {
+		langRU: "Примеры:\n" +
+			"/pause",
+		langEN: "Examples:\n" +
+			"/pause",
+		langUK: "Приклади:\n" +
+			"/pause",
+	},
+	"cmd.resume": {
+		langRU: "Возобновить рассылку",
+		langEN: "Resume notifications",
+		langUK: "Відновити розсилку",
+	},
+	"cmd.resume.usage": {
+		langRU: "Примеры:\n" +
+			"/resume",
+		langEN: "Examples:\n" +
+			"/resume",
+		langUK: "Приклади:\n" +
+			"/resume",
+	},
+	"cmd.force_update": {
+		langRU: "Обновить ставки немедленно",
+		langEN: "Update rates now",
+		langUK: "Оновити ставки негайно",
+	},
+	"cmd.force_update.usage": {
+		langRU: "Примеры:\n" +
+			"/force_update",
+		langEN: "Examples:\n" +
+			"/force_update",
+		langUK: "Приклади:\n" +
+			"/force_update",
+	},
+	"cmd.invite": {
+		langRU: "Код приглашения для закрытого режима",
+		langEN: "Invite code for private mode",
+		langUK: "Код запрошення для закритого режиму",
+	},
+	"cmd.invite.usage": {
+		langRU: "Примеры:\n" +
+			"/invite — действует 24 часа\n" +
+			"/invite 12h",
+		langEN: "Examples:\n" +
+			"/invite — valid for 24 hours\n" +
+			"/invite 12h",
+		langUK: "Приклади:\n" +
+			"/invite — діє 24 години\n" +
+			"/invite 12h",
+	},
+}
@@ -0,0 +1,349 @@
+package bot
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/mail"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	exchanges "github.com/petrixs/cr-exchanges"
+)
+
+// Рассылка не зависит от канала: цикл рассылки отбирает получателей по расписанию,
+// собирает для каждого ratesAlert и передаёт его Notifier канала, который сам оформляет сообщение.
+
+// Каналы доставки
+const (
+	channelTelegram = "telegram"
+	channelDiscord  = "discord"
+	channelSlack    = "slack"
+	channelWebhook  = "webhook"
+	channelEmail    = "email"
+)
+
+// Notifier канал доставки рассылок
+type Notifier interface {
+	// Channel название канала: telegram, discord, slack, webhook, email
+	Channel() string
+	// NotifyRates отправляет получателю плановую рассылку ставок
+	NotifyRates(to recipient, alert *ratesAlert) error
+	// NotifyText отправляет получателю объявление администратора (HTML-разметка Telegram)
+	NotifyText(to recipient, text string) error
+}
+
+// ratesAlert плановая рассылка одному получателю: снимок ставок и параметры отбора.
+// Сколько строк на биржу показывать, решает канал, поэтому отчёт строится в Notifier через report.
+type ratesAlert struct {
+	Rates   map[string][]exchanges.FundingRate
+	Prices  priceIndex
+	Options reportOptions
+}
+
+// report отчёт для канала, показывающего не больше rowLimit ставок на биржу (0 — все)
+func (a *ratesAlert) report(rowLimit int) ratesReport {
+	opts := a.Options
+	opts.RowLimit = rowLimit
+	return buildRatesReport(a.Rates, a.Prices, opts)
+}
+
+// recipient получатель рассылки: чат Telegram или цель внешнего канала
+type recipient struct {
+	// Key уникальный ключ для расписания: telegram:123, discord:desk
+	Key      string
+	Notifier Notifier
+	// ChatID чат Telegram; URL и Headers — адрес вебхука внешнего канала; Emails — адреса для email
+	ChatID  int64
+	URL     string
+	Headers map[string]string
+	Emails  []string
+
+	Settings   ChatSettings
+	Thresholds rateThresholds
+	// Exchanges биржи, ставки которых получает цель; пусто — все
+	Exchanges rateFilter
+	Lang      string
+	Loc       *time.Location
+}
+
+// reportOptions параметры отчёта по порогам, расписанию и языку получателя
+func (to recipient) reportOptions(now time.Time) reportOptions {
+	return reportOptions{
+		Thresholds: to.Thresholds,
+		MinVolume:  to.Settings.MinVolume,
+		Exchanges:  to.Exchanges,
+		Digest:     len(to.Settings.Digest) > 0,
+		Lang:       to.Lang,
+		Loc:        to.Loc,
+		Time:       now,
+	}
+}
+
+// telegramRecipient получатель для подписанного чата Telegram
+func (b *Bot) telegramRecipient(chatID int64) recipient {
+	return recipient{
+		Key:        channelTelegram + ":" + strconv.FormatInt(chatID, 10),
+		Notifier:   b.notifiers[channelTelegram],
+		ChatID:     chatID,
+		Settings:   getChatSettings(chatID),
+		Thresholds: getRateThresholds(chatID),
+		Lang:       chatLang(chatID),
+		Loc:        chatLocation(chatID),
+	}
+}
+
+// recipients все получатели рассылки: подписанные чаты с доступом и цели внешних каналов
+func (b *Bot) recipients() []recipient {
+	subscribersLock.Lock()
+	ids := make([]int64, 0, len(subscribers))
+	for id := range subscribers {
+		ids = append(ids, id)
+	}
+	subscribersLock.Unlock()
+
+	var list []recipient
+	for _, chatID := range ids {
+		if chatHasAccess(chatID) {
+			list = append(list, b.telegramRecipient(chatID))
+		}
+	}
+
+	b.targetsLock.Lock()
+	list = append(list, b.targets...)
+	b.targetsLock.Unlock()
+	return list
+}
+
+// telegramNotifier доставка в чаты Telegram через очередь исходящих сообщений
+type telegramNotifier struct {
+	b *Bot
+}
+
+func (n telegramNotifier) Channel() string { return channelTelegram }
+
+// NotifyRates отправляет ставки в HTML с блоками <pre>; ошибки доставки обрабатывает диспетчер
+func (n telegramNotifier) NotifyRates(to recipient, alert *ratesAlert) error {
+	n.b.sendLongMessage(to.ChatID, renderTelegramHTML(alert.report(telegramRowLimit)))
+	return nil
+}
+
+func (n telegramNotifier) NotifyText(to recipient, text string) error {
+	n.b.sendLongMessage(to.ChatID, text)
+	return nil
+}
+
+// NotifyTarget получатель во внешнем канале из файла NOTIFY_TARGETS.
+// Пороги и расписание задаются так же, как командами бота: "0.05%", "5M", "15m", "23:00-08:00".
+type NotifyTarget struct {
+	Name    string            `json:"name"`
+	Channel string            `json:"channel"`
+	URL     string            `json:"url,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	To      []string          `json:"to,omitempty"`
+
+	Threshold         string   `json:"threshold,omitempty"`
+	NegativeThreshold string   `json:"negative_threshold,omitempty"`
+	Direction         string   `json:"direction,omitempty"`
+	MinVolume         string   `json:"min_volume,omitempty"`
+	Exchanges         []string `json:"exchanges,omitempty"`
+	Every             string   `json:"every,omitempty"`
+	Quiet             string   `json:"quiet,omitempty"`
+	Digest            []string `json:"digest,omitempty"`
+	Timezone          string   `json:"timezone,omitempty"`
+	Lang              string   `json:"lang,omitempty"`
+}
+
+// newExternalNotifiers каналы для целей из NOTIFY_TARGETS
+func newExternalNotifiers() map[string]Notifier {
+	notifiers := newWebhookNotifiers()
+	notifiers[channelEmail] = emailNotifier{}
+	return notifiers
+}
+
+// notifyTargetsFile путь к файлу целей внешних каналов
+func notifyTargetsFile() string {
+	if path := os.Getenv("NOTIFY_TARGETS"); path != "" {
+		return path
+	}
+	return "notify.json"
+}
+
+// readNotifyTargets читает цели из файла; отсутствующий файл — пустой список
+func readNotifyTargets(path string) ([]NotifyTarget, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var targets []NotifyTarget
+	if err := json.Unmarshal(data, &targets); err != nil {
+		return nil, fmt.Errorf("повреждён: %v", err)
+	}
+	return targets, nil
+}
+
+// recipient проверяет настройки цели и превращает её в получателя рассылки
+func (t NotifyTarget) recipient(notifiers map[string]Notifier) (recipient, error) {
+	if t.Name == "" {
+		return recipient{}, fmt.Errorf("не задано имя")
+	}
+	notifier, ok := notifiers[t.Channel]
+	if !ok || t.Channel == channelTelegram {
+		return recipient{}, fmt.Errorf("неизвестный канал %q, ожидается discord, slack, webhook или email", t.Channel)
+	}
+
+	to := recipient{
+		Key:      t.Channel + ":" + t.Name,
+		Notifier: notifier,
+		Lang:     defaultLang(),
+		Loc:      defaultLocation(),
+	}
+	if t.Channel == channelEmail {
+		if len(t.To) == 0 {
+			return recipient{}, fmt.Errorf("не заданы адреса to")
+		}
+		for _, value := range t.To {
+			address, err := mail.ParseAddress(value)
+			if err != nil {
+				return recipient{}, fmt.Errorf("некорректный адрес %q", value)
+			}
+			to.Emails = append(to.Emails, address.Address)
+		}
+	} else {
+		if !strings.HasPrefix(t.URL, "https://") && !strings.HasPrefix(t.URL, "http://") {
+			return recipient{}, fmt.Errorf("некорректный url %q", t.URL)
+		}
+		to.URL = t.URL
+		to.Headers = t.Headers
+	}
+
+	to.Thresholds.Positive = getDefaultThreshold()
+	if t.Threshold != "" {
+		value, err := parseThresholdValue(t.Threshold)
+		if err != nil {
+			return recipient{}, fmt.Errorf("некорректный threshold %q", t.Threshold)
+		}
+		to.Thresholds.Positive = value
+	}
+	to.Thresholds.Negative = to.Thresholds.Positive
+	if t.NegativeThreshold != "" {
+		value, err := parseThresholdValue(t.NegativeThreshold)
+		if err != nil {
+			return recipient{}, fmt.Errorf("некорректный negative_threshold %q", t.NegativeThreshold)
+		}
+		to.Thresholds.Negative = value
+		to.Settings.NegativeThreshold = value
+	}
+	switch t.Direction {
+	case directionAny, directionPositive, directionNegative:
+		to.Thresholds.Direction = t.Direction
+		to.Settings.Direction = t.Direction
+	default:
+		return recipient{}, fmt.Errorf("некорректный direction %q, ожидается positive или negative", t.Direction)
+	}
+
+	if t.MinVolume != "" {
+		amount, err := parseAmount(t.MinVolume)
+		if err != nil {
+			return recipient{}, fmt.Errorf("некорректный min_volume %q", t.MinVolume)
+		}
+		to.Settings.MinVolume = amount
+	}
+	if len(t.Exchanges) > 0 {
+		to.Exchanges.addExchanges(strings.Join(t.Exchanges, ","))
+	}
+
+	if t.Every != "" {
+		every, err := time.ParseDuration(t.Every)
+		if err != nil || every < minBroadcastInterval {
+			return recipient{}, fmt.Errorf("некорректный every %q, минимум %v", t.Every, minBroadcastInterval)
+		}
+		to.Settings.EveryMinutes = int(every.Minutes())
+	}
+	if t.Quiet != "" {
+		start, end, ok := strings.Cut(t.Quiet, "-")
+		var err error
+		if ok {
+			if to.Settings.QuietStart, err = parseClockString(strings.TrimSpace(start)); err == nil {
+				to.Settings.QuietEnd, err = parseClockString(strings.TrimSpace(end))
+			}
+		}
+		if !ok || err != nil {
+			return recipient{}, fmt.Errorf("некорректный quiet %q, ожидается 23:00-08:00", t.Quiet)
+		}
+	}
+	for _, value := range t.Digest {
+		clock, err := parseClockString(value)
+		if err != nil {
+			return recipient{}, fmt.Errorf("некорректное время digest %q", value)
+		}
+		to.Settings.Digest = append(to.Settings.Digest, clock)
+	}
+	sort.Strings(to.Settings.Digest)
+
+	if t.Timezone != "" {
+		loc, err := loadTimezone(t.Timezone)
+		if err != nil {
+			return recipient{}, fmt.Errorf("неизвестный часовой пояс %q", t.Timezone)
+		}
+		to.Settings.Timezone = t.Timezone
+		to.Loc = loc
+	}
+	if t.Lang != "" {
+		lang := normalizeLang(t.Lang)
+		if lang == "" {
+			return recipient{}, fmt.Errorf("язык %q не поддерживается", t.Lang)
+		}
+		to.Settings.Lang = lang
+		to.Lang = lang
+	}
+	return to, nil
+}
+
+// loadTargets перечитывает цели внешних каналов; некорректные цели пропускаются с записью в лог
+func (b *Bot) loadTargets() {
+	path := notifyTargetsFile()
+	targets, err := readNotifyTargets(path)
+	if err != nil {
+		log.Printf("Не удалось прочитать %s: %v", path, err)
+		return
+	}
+
+	var list []recipient
+	seen := make(map[string]struct{})
+	for _, target := range targets {
+		to, err := target.recipient(b.notifiers)
+		if err == nil {
+			err = to.Exchanges.validateExchanges(b.exchanges)
+		}
+		if err == nil {
+			if _, dup := seen[to.Key]; dup {
+				err = fmt.Errorf("имя повторяется")
+			}
+		}
+		if err != nil {
+			log.Printf("Цель %q в %s пропущена: %v", target.Name, path, err)
+			continue
+		}
+		seen[to.Key] = struct{}{}
+		list = append(list, to)
+	}
+
+	b.targetsLock.Lock()
+	b.targets = list
+	b.targetsLock.Unlock()
+	log.Printf("Загружено получателей во внешних каналах: %d", len(list))
+}
+
+// targetsCount количество получателей во внешних каналах
+func (b *Bot) targetsCount() int {
+	b.targetsLock.Lock()
+	defer b.targetsLock.Unlock()
+	return len(b.targets)
+}
@@ -0,0 +1,271 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	exchanges "github.com/petrixs/cr-exchanges"
+)
+
+const (
+	ratesPageSize        = 20
+	ratesExchangesPerRow = 3
+	ratesCallbackPrefix  = "rates:"
+	ratesCallbackNoop    = "rates:noop"
+)
+
+// Режимы сортировки в /rates
+const (
+	sortByAbsRate     = "abs"
+	sortByPositive    = "pos"
+	sortByNegative    = "neg"
+	sortByVolume      = "vol"
+	sortByNextFunding = "next"
+)
+
+// ratesSortModes кнопки сортировки в порядке отображения; названия режимов — в каталоге под ключами sort.<режим>
+var ratesSortModes = []struct {
+	Mode  string
+	Label string
+}{
+	{sortByAbsRate, "|%|"},
+	{sortByPositive, "⬆️ +"},
+	{sortByNegative, "⬇️ −"},
+	{sortByVolume, "💰 Vol"},
+	{sortByNextFunding, "⏰ Next"},
+}
+
+// ratesView состояние постраничного просмотра /rates, хранится в callback data кнопок
+type ratesView struct {
+	Exchange int
+	Sort     string
+	Page     int
+}
+
+func (v ratesView) callbackData() string {
+	return fmt.Sprintf("%s%d:%s:%d", ratesCallbackPrefix, v.Exchange, v.Sort, v.Page)
+}
+
+// parseRatesView разбирает callback data вида rates:<биржа>:<сортировка>:<страница>
+func parseRatesView(data string) (ratesView, bool) {
+	parts := strings.Split(strings.TrimPrefix(data, ratesCallbackPrefix), ":")
+	if len(parts) != 3 {
+		return ratesView{}, false
+	}
+	exchange, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return ratesView{}, false
+	}
+	page, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return ratesView{}, false
+	}
+	return ratesView{Exchange: exchange, Sort: parts[1], Page: page}, true
+}
+
+// validSortMode проверяет, что режим сортировки существует
+func validSortMode(mode string) bool {
+	for _, m := range ratesSortModes {
+		if m.Mode == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// sortTitle возвращает название режима сортировки
+func sortTitle(lang, mode string) string {
+	return tr(lang, "sort."+mode)
+}
+
+// nextFundingTime разбирает время следующей выплаты
+func nextFundingTime(rate exchanges.FundingRate) (time.Time, bool) {
+	if rate.NextFunding == "Неизвестно" {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339, rate.NextFunding)
+	return t, err == nil
+}
+
+// selectRates фильтрует ставки по порогу, объёму и режиму и сортирует копию, не трогая кэш
+func selectRates(rates []exchanges.FundingRate, thresholds rateThresholds, minVolume float64, prices priceIndex, mode string) []exchanges.FundingRate {
+	selected := make([]exchanges.FundingRate, 0, len(rates))
+	for _, rate := range rates {
+		if !thresholds.pass(rate.Rate) || !prices.passesMinVolume(rate, minVolume) {
+			continue
+		}
+		if mode == sortByPositive && rate.Rate <= 0 || mode == sortByNegative && rate.Rate >= 0 {
+			continue
+		}
+		selected = append(selected, rate)
+	}
+
+	sort.SliceStable(selected, func(i, j int) bool {
+		a, b := selected[i], selected[j]
+		switch mode {
+		case sortByPositive:
+			return a.Rate > b.Rate
+		case sortByNegative:
+			return a.Rate < b.Rate
+		case sortByVolume:
+			return prices.usdVolume(a) > prices.usdVolume(b)
+		case sortByNextFunding:
+			ta, okA := nextFundingTime(a)
+			tb, okB := nextFundingTime(b)
+			if okA != okB {
+				return okA
+			}
+			return ta.Before(tb)
+		default:
+			return math.Abs(a.Rate) > math.Abs(b.Rate)
+		}
+	})
+	return selected
+}
+
+// renderRatesView формирует страницу /rates и клавиатуру навигации
+func (b *Bot) renderRatesView(chatID int64, view ratesView, lang string) (string, *tgbotapi.InlineKeyboardMarkup) {
+	if view.Exchange < 0 || view.Exchange >= len(b.exchanges) {
+		view.Exchange = 0
+	}
+	if !validSortMode(view.Sort) {
+		view.Sort = sortByAbsRate
+	}
+
+	exchangeName := b.exchanges[view.Exchange].GetName()
+	thresholds := getRateThresholds(chatID)
+	minVolume := getMinVolume(chatID)
+	prices := buildPriceIndex(b.cache.GetAllRates())
+	rates := selectRates(b.cache.GetRates(exchangeName), thresholds, minVolume, prices, view.Sort)
+
+	pages := (len(rates) + ratesPageSize - 1) / ratesPageSize
+	if pages == 0 {
+		pages = 1
+	}
+	// Листание по кругу
+	view.Page = ((view.Page % pages) + pages) % pages
+
+	text := tr(lang, "rates.page_header", exchangeName, sortTitle(lang, view.Sort), view.Page+1, pages)
+	if len(rates) == 0 {
+		text += tr(lang, "rates.view_empty")
+	} else {
+		start := view.Page * ratesPageSize
+		end := start + ratesPageSize
+		if end > len(rates) {
+			end = len(rates)
+		}
+		text += rateLinesHTML(rates[start:end], chatLocation(chatID), time.Now(), lang) + "\n" +
+			tr(lang, "rates.shown", start+1, end, len(rates), thresholds.describe(lang))
+		if minVolume > 0 {
+			text += tr(lang, "rates.min_volume", formatAmount(minVolume))
+		}
+		text += ")</i>"
+	}
+
+	return text, b.ratesKeyboard(view, pages)
+}
+
+// rateLinesHTML блок <pre> со строками ставок; текст экранируется: до выплаты бывает «<1м»
+func rateLinesHTML(rates []exchanges.FundingRate, loc *time.Location, now time.Time, lang string) string {
+	lines := make([]string, 0, len(rates))
+	for _, rate := range rates {
+		lines = append(lines, formatRateLine(rate, loc, now, lang))
+	}
+	return "<pre>" + escapeHTML(strings.Join(lines, "\n")) + "</pre>"
+}
+
+// ratesKeyboard строит кнопки переключения бирж, сортировки и страниц
+func (b *Bot) ratesKeyboard(view ratesView, pages int) *tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+
+	var row []tgbotapi.InlineKeyboardButton
+	for i, ex := range b.exchanges {
+		label := ex.GetName()
+		if i == view.Exchange {
+			label = "• " + label
+		}
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(label,
+			ratesView{Exchange: i, Sort: view.Sort}.callbackData()))
+		if len(row) == ratesExchangesPerRow {
+			rows = append(rows, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+
+	row = nil
+	for _, m := range ratesSortModes {
+		label := m.Label
+		if m.Mode == view.Sort {
+			label = "• " + label
+		}
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(label,
+			ratesView{Exchange: view.Exchange, Sort: m.Mode}.callbackData()))
+	}
+	rows = append(rows, row)
+
+	if pages > 1 {
+		prev, next := view, view
+		prev.Page--
+		next.Page++
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("◀️", prev.callbackData()),
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("%d/%d", view.Page+1, pages), ratesCallbackNoop),
+			tgbotapi.NewInlineKeyboardButtonData("▶️", next.callbackData()),
+		))
+	}
+
+	markup := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	return &markup
+}
+
+// firstExchangeWithRates возвращает индекс первой биржи, у которой есть ставки, проходящие фильтры чата
+func (b *Bot) firstExchangeWithRates(chatID int64) int {
+	thresholds := getRateThresholds(chatID)
+	minVolume := getMinVolume(chatID)
+	prices := buildPriceIndex(b.cache.GetAllRates())
+	for i, ex := range b.exchanges {
+		if len(selectRates(b.cache.GetRates(ex.GetName()), thresholds, minVolume, prices, sortByAbsRate)) > 0 {
+			return i
+		}
+	}
+	return 0
+}
+
+// handleRatesCallback перерисовывает сообщение /rates по нажатию кнопки
+func (b *Bot) handleRatesCallback(query *tgbotapi.CallbackQuery) {
+	if query.Data == ratesCallbackNoop {
+		b.answerCallback(query, "")
+		return
+	}
+
+	chatID := query.Message.Chat.ID
+	lang := userLang(chatID, query.From)
+	view, ok := parseRatesView(query.Data)
+	if !ok {
+		b.answerCallback(query, tr(lang, "callback.unknown_button"))
+		return
+	}
+
+	text, markup := b.renderRatesView(chatID, view, lang)
+	b.answerCallback(query, "")
+
+	_, err := b.deliver([]*outgoingMessage{{
+		Target:        chatTarget{ChatID: chatID},
+		Text:          text,
+		Markup:        markup,
+		EditMessageID: query.Message.MessageID,
+		Priority:      priorityReply,
+	}})
+	if err != nil {
+		log.Printf("Ошибка обновления сообщения /rates в чате %d: %v", chatID, err)
+	}
+}
@@ -0,0 +1,30 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	exchanges "github.com/petrixs/cr-exchanges"
+)
+
+func TestRateLinesHTMLEscapesImminentFunding(t *testing.T) {
+	now := time.Date(2026, 1, 1, 7, 59, 30, 0, time.UTC)
+	rates := []exchanges.FundingRate{{
+		Symbol:      "BTCUSDT",
+		Rate:        0.002,
+		NextFunding: now.Add(30 * time.Second).Format(time.RFC3339),
+	}}
+
+	text := rateLinesHTML(rates, time.UTC, now, langRU)
+	if !strings.Contains(text, "&lt;1м") {
+		t.Errorf("до выплаты меньше минуты, ожидается «&lt;1м»: %q", text)
+	}
+	body := strings.TrimSuffix(strings.TrimPrefix(text, "<pre>"), "</pre>")
+	if strings.ContainsAny(body, "<>") {
+		t.Errorf("в блоке <pre> остались неэкранированные символы: %q", body)
+	}
+	if parts := splitHTML(text, telegramTextLimit); len(parts) != 1 || parts[0] != text {
+		t.Errorf("разметка разбирается неверно: %q", parts)
+	}
+}
@@ -0,0 +1,150 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Рендеры отчёта о ставках. Telegram HTML — основной формат бота, остальные нужны внешним каналам;
+// Discord и Slack собираются в webhooks.go рядом с типами их сообщений.
+
+// reportTitle заголовок отчёта без разметки
+func reportTitle(r ratesReport) string {
+	if r.Digest {
+		return tr(r.Lang, "notify.digest_title")
+	}
+	return tr(r.Lang, "notify.title")
+}
+
+// reportFooter пороги и минимальный объём получателя
+func reportFooter(r ratesReport) string {
+	text := tr(r.Lang, "notify.footer", r.Thresholds.describe(r.Lang))
+	if r.MinVolume > 0 {
+		text += tr(r.Lang, "rates.min_volume", formatAmount(r.MinVolume))
+	}
+	return text
+}
+
+// sectionLines строки ставок биржи, как в блоках <pre>
+func sectionLines(r ratesReport, section reportSection) []string {
+	lines := make([]string, len(section.Rates))
+	for i, rate := range section.Rates {
+		lines[i] = formatRateLine(rate, r.Loc, r.Time, r.Lang)
+	}
+	return lines
+}
+
+// renderTelegramHTML сообщение Telegram в режиме HTML: блок <pre> на каждую биржу
+func renderTelegramHTML(r ratesReport) string {
+	var result []string
+	if r.Digest {
+		result = append(result, tr(r.Lang, "digest.header"))
+	}
+	if r.Empty() {
+		return strings.Join(append(result, tr(r.Lang, "rates.none_above")), "\n")
+	}
+	for _, section := range r.Sections {
+		result = append(result, fmt.Sprintf("\n<b>📈 %s</b>", escapeHTML(section.Exchange)))
+		result = append(result, "<pre>"+escapeHTML(strings.Join(sectionLines(r, section), "\n"))+"</pre>")
+		if section.Hidden > 0 {
+			result = append(result, trn(r.Lang, "rates.more", section.Hidden))
+		}
+		result = append(result, "────────────────────────────")
+	}
+	return strings.Join(result, "\n")
+}
+
+// markdownV2Escape экранирование текста вне блоков кода в MarkdownV2
+var markdownV2Escape = strings.NewReplacer(
+	`\`, `\\`, "_", `\_`, "*", `\*`, "[", `\[`, "]", `\]`, "(", `\(`, ")", `\)`, "~", `\~`, "`", "\\`",
+	">", `\>`, "#", `\#`, "+", `\+`, "-", `\-`, "=", `\=`, "|", `\|`, "{", `\{`, "}", `\}`, ".", `\.`, "!", `\!`,
+)
+
+// markdownV2CodeEscape внутри блоков кода экранируются только ` и \
+var markdownV2CodeEscape = strings.NewReplacer(`\`, `\\`, "`", "\\`")
+
+// renderMarkdownV2 сообщение Telegram в режиме MarkdownV2
+func renderMarkdownV2(r ratesReport) string {
+	var result []string
+	result = append(result, "*"+markdownV2Escape.Replace(reportTitle(r))+"*")
+	if r.Empty() {
+		return strings.Join(append(result, "_"+markdownV2Escape.Replace(tr(r.Lang, "notify.none"))+"_"), "\n")
+	}
+	for _, section := range r.Sections {
+		result = append(result, "\n*📈 "+markdownV2Escape.Replace(section.Exchange)+"*")
+		result = append(result, "```\n"+markdownV2CodeEscape.Replace(strings.Join(sectionLines(r, section), "\n"))+"\n```")
+		if section.Hidden > 0 {
+			result = append(result, "_"+markdownV2Escape.Replace(trn(r.Lang, "notify.more", section.Hidden))+"_")
+		}
+	}
+	result = append(result, "\n"+markdownV2Escape.Replace(reportFooter(r)))
+	return strings.Join(result, "\n")
+}
+
+// renderPlain отчёт простым текстом: для текстовой версии писем и логов
+func renderPlain(r ratesReport) string {
+	var b strings.Builder
+	b.WriteString(reportTitle(r) + "\n")
+	if r.Empty() {
+		b.WriteString("\n" + tr(r.Lang, "notify.none") + "\n")
+	}
+	for _, section := range r.Sections {
+		fmt.Fprintf(&b, "\n📈 %s\n", section.Exchange)
+		for _, line := range sectionLines(r, section) {
+			b.WriteString(line + "\n")
+		}
+		if section.Hidden > 0 {
+			b.WriteString(trn(r.Lang, "notify.more", section.Hidden) + "\n")
+		}
+	}
+	b.WriteString("\n" + reportFooter(r) + "\n")
+	return b.String()
+}
+
+// jsonReport отчёт для машинной обработки: ставки в формате выгрузки
+type jsonReport struct {
+	Type       string        `json:"type"`
+	Time       time.Time     `json:"time"`
+	Digest     bool          `json:"digest,omitempty"`
+	Thresholds jsonThreshold `json:"thresholds"`
+	Rates      []exportRow   `json:"rates"`
+	// Hidden сколько ставок отброшено ограничением строк по биржам
+	Hidden map[string]int `json:"hidden,omitempty"`
+}
+
+type jsonThreshold struct {
+	Positive  float64 `json:"positive"`
+	Negative  float64 `json:"negative"`
+	Direction string  `json:"direction,omitempty"`
+	MinVolume float64 `json:"min_volume,omitempty"`
+}
+
+// renderJSON отчёт в виде, который кодируется в JSON без потерь
+func renderJSON(r ratesReport) jsonReport {
+	now := r.Time.Truncate(time.Second)
+	report := jsonReport{
+		Type:   "rates",
+		Time:   now,
+		Digest: r.Digest,
+		Thresholds: jsonThreshold{
+			Positive:  r.Thresholds.Positive,
+			Negative:  r.Thresholds.Negative,
+			Direction: r.Thresholds.Direction,
+			MinVolume: r.MinVolume,
+		},
+		Rates: []exportRow{},
+	}
+	for _, section := range r.Sections {
+		for _, rate := range section.Rates {
+			report.Rates = append(report.Rates, newExportRow(section.Exchange, rate, now))
+		}
+		if section.Hidden > 0 {
+			if report.Hidden == nil {
+				report.Hidden = make(map[string]int)
+			}
+			report.Hidden[section.Exchange] = section.Hidden
+		}
+	}
+	return report
+}
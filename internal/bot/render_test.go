@@ -0,0 +1,67 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	exchanges "github.com/petrixs/cr-exchanges"
+)
+
+// markdownV2Reserved символы, которые MarkdownV2 требует экранировать вне блоков кода
+const markdownV2Reserved = "_*[]()~`>#+-=|{}.!"
+
+func TestRenderMarkdownV2Escapes(t *testing.T) {
+	exchange := "Ex" + markdownV2Reserved
+	symbol := "A" + markdownV2Reserved + `\`
+	rates := map[string][]exchanges.FundingRate{
+		exchange: {{Symbol: symbol, Rate: 0.002, NextFunding: "Неизвестно"}},
+	}
+	report := buildRatesReport(rates, buildPriceIndex(rates), reportOptions{
+		Thresholds: rateThresholds{Positive: 0.001, Negative: 0.001},
+		Lang:       langEN,
+		Loc:        time.UTC,
+		Time:       time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+	})
+	text := renderMarkdownV2(report)
+
+	// Название биржи вне блока кода: экранирован каждый зарезервированный символ
+	var escapedExchange strings.Builder
+	escapedExchange.WriteString("Ex")
+	for _, c := range markdownV2Reserved {
+		escapedExchange.WriteString(`\` + string(c))
+	}
+	if !strings.Contains(text, "*📈 "+escapedExchange.String()+"*") {
+		t.Errorf("название биржи не экранировано: %q", text)
+	}
+
+	// Внутри блока кода экранируются только ` и \
+	codeSymbol := strings.NewReplacer("`", "\\`", `\`, `\\`).Replace(symbol)
+	if !strings.Contains(text, "```\n"+codeSymbol) {
+		t.Errorf("тикер в блоке кода экранирован неверно, ожидается %q: %q", codeSymbol, text)
+	}
+
+	// Вне блоков кода не осталось неэкранированных зарезервированных символов
+	outside := text
+	for {
+		start := strings.Index(outside, "```")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(outside[start+3:], "```")
+		if end < 0 {
+			t.Fatalf("незакрытый блок кода: %q", text)
+		}
+		outside = outside[:start] + outside[start+3+end+3:]
+	}
+	for i := 0; i < len(outside); i++ {
+		c := outside[i]
+		if c == '\\' {
+			i++
+			continue
+		}
+		if strings.IndexByte(markdownV2Reserved, c) >= 0 && c != '*' && c != '_' {
+			t.Errorf("неэкранированный символ %q в позиции %d: %q", c, i, outside)
+		}
+	}
+}
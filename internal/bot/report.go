@@ -0,0 +1,74 @@
+package bot
+
+import (
+	"sort"
+	"time"
+
+	exchanges "github.com/petrixs/cr-exchanges"
+)
+
+// Отчёт о ставках для рассылок собирается конвейером без побочных эффектов:
+// фильтр → сортировка → группировка по биржам → усечение. Оформление — дело рендеров в render.go.
+
+// telegramRowLimit сколько ставок биржи показывать в сообщении Telegram
+const telegramRowLimit = 20
+
+// reportOptions параметры отбора и оформления отчёта
+type reportOptions struct {
+	Thresholds rateThresholds
+	MinVolume  float64
+	// Exchanges только эти биржи; пусто — все
+	Exchanges rateFilter
+	// RowLimit сколько ставок биржи оставить; 0 — все
+	RowLimit int
+	Digest   bool
+	Lang     string
+	Loc      *time.Location
+	Time     time.Time
+}
+
+// ratesReport модель представления: ставки уже отобраны, отсортированы, сгруппированы и усечены
+type ratesReport struct {
+	reportOptions
+	Sections []reportSection
+}
+
+// reportSection ставки одной биржи по убыванию модуля ставки
+type reportSection struct {
+	Exchange string
+	Rates    []exchanges.FundingRate
+	// Hidden сколько ставок отброшено ограничением RowLimit
+	Hidden int
+}
+
+// Empty нет ни одной ставки выше порога
+func (r ratesReport) Empty() bool {
+	return len(r.Sections) == 0
+}
+
+// buildRatesReport строит отчёт; кэш не изменяется — сортируются копии.
+// prices строится по всем биржам, чтобы объём в контрактах пересчитывался и при фильтре бирж.
+func buildRatesReport(rates map[string][]exchanges.FundingRate, prices priceIndex, opts reportOptions) ratesReport {
+	names := make([]string, 0, len(rates))
+	for name := range rates {
+		if opts.Exchanges.matchExchange(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	report := ratesReport{reportOptions: opts}
+	for _, name := range names {
+		selected := selectRates(rates[name], opts.Thresholds, opts.MinVolume, prices, sortByAbsRate)
+		if len(selected) == 0 {
+			continue
+		}
+		section := reportSection{Exchange: name, Rates: selected}
+		if opts.RowLimit > 0 && len(selected) > opts.RowLimit {
+			section.Rates = selected[:opts.RowLimit]
+			section.Hidden = len(selected) - opts.RowLimit
+		}
+		report.Sections = append(report.Sections, section)
+	}
+	return report
+}
@@ -0,0 +1,252 @@
+package bot
+
+import (
+	"log"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	// commandBurst сколько команд подряд пользователь может отправить без ожидания
+	commandBurst = 5
+	// commandRefill за это время пользователю возвращается одна команда из лимита
+	commandRefill = 3 * time.Second
+)
+
+// request входящая команда, которую обрабатывает цепочка middleware
+type request struct {
+	Msg      *tgbotapi.Message
+	ThreadID int
+	// Name имя команды без / и @бота в нижнем регистре
+	Name string
+	// Command команда из реестра; nil, если такой команды нет
+	Command *command
+	// Text аргументы одной строкой без пробелов по краям, как их ввёл пользователь
+	Text string
+	// Args аргументы команды, разделённые пробелами
+	Args []string
+	Lang string
+}
+
+// Arg i-й аргумент команды или пустая строка
+func (r *request) Arg(i int) string {
+	if i < len(r.Args) {
+		return r.Args[i]
+	}
+	return ""
+}
+
+// handlerFunc обработчик запроса
+type handlerFunc func(r *request)
+
+// middleware оборачивает обработчик дополнительной логикой
+type middleware func(next handlerFunc) handlerFunc
+
+// chain собирает обработчик из middleware; первое в списке выполняется первым
+func chain(handler handlerFunc, middlewares ...middleware) handlerFunc {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// newRouter собирает цепочку обработки команд
+func (b *Bot) newRouter() handlerFunc {
+	return chain(b.route,
+		b.recoverPanics,
+		logRequests,
+		b.trackChat,
+		parseArgs,
+		// Лимит раньше проверки доступа: иначе коды приглашения из /start и /redeem можно перебирать без ограничений
+		b.rateLimit,
+		b.checkAccess,
+		b.checkAdmin,
+		b.checkArgs,
+	)
+}
+
+// route вызывает обработчик команды из реестра
+func (b *Bot) route(r *request) {
+	r.Command.Handler(b, r)
+}
+
+// recoverPanics не даёт панике в обработчике уронить бота: пишет стек в лог и сообщает об ошибке
+func (b *Bot) recoverPanics(next handlerFunc) handlerFunc {
+	return func(r *request) {
+		defer func() {
+			if err := recover(); err != nil {
+				log.Printf("Паника при обработке /%s в чате %d: %v\n%s", r.Name, r.Msg.Chat.ID, err, debug.Stack())
+				b.reply(r.Msg, tr(msgLang(r.Msg), "error.internal"))
+			}
+		}()
+		next(r)
+	}
+}
+
+// logRequests пишет в лог команду, отправителя и время обработки
+func logRequests(next handlerFunc) handlerFunc {
+	return func(r *request) {
+		started := time.Now()
+		next(r)
+		if r.Command != nil {
+			log.Printf("Команда /%s от %s в чате %d обработана за %s",
+				r.Name, senderName(r.Msg), r.Msg.Chat.ID, time.Since(started).Round(time.Millisecond))
+		}
+	}
+}
+
+// trackChat запоминает чат, язык собеседника и тему форума, из которой пришла команда
+func (b *Bot) trackChat(next handlerFunc) handlerFunc {
+	return func(r *request) {
+		if r.ThreadID != 0 {
+			b.messageThreads.Store(r.Msg, r.ThreadID)
+			defer b.messageThreads.Delete(r.Msg)
+		}
+		rememberChat(r.Msg.Chat)
+		rememberLanguage(r.Msg)
+		r.Lang = msgLang(r.Msg)
+		next(r)
+	}
+}
+
+// checkAccess в закрытом режиме пропускает только одобренные чаты; остальным предлагает код приглашения
+func (b *Bot) checkAccess(next handlerFunc) handlerFunc {
+	return func(r *request) {
+		if !hasAccess(r.Msg) {
+			b.handleUnapproved(r)
+			return
+		}
+		if r.Command == nil {
+			return
+		}
+		next(r)
+	}
+}
+
+// rateLimit ограничивает частоту команд от одного пользователя; администраторы не ограничиваются
+func (b *Bot) rateLimit(next handlerFunc) handlerFunc {
+	return func(r *request) {
+		if isAdmin(r.Msg) {
+			next(r)
+			return
+		}
+		// В каналах отправителя нет, ограничиваем канал целиком
+		key := r.Msg.Chat.ID
+		if r.Msg.From != nil {
+			key = r.Msg.From.ID
+		}
+		allowed, warn := b.limiter.allow(key, time.Now())
+		if !allowed {
+			log.Printf("Превышен лимит команд: %s в чате %d (/%s)", senderName(r.Msg), r.Msg.Chat.ID, r.Name)
+			if warn {
+				b.reply(r.Msg, tr(r.Lang, "ratelimit.exceeded"))
+			}
+			return
+		}
+		next(r)
+	}
+}
+
+// checkAdmin отклоняет админские команды из чатов, не входящих в ADMIN_CHAT_IDS
+func (b *Bot) checkAdmin(next handlerFunc) handlerFunc {
+	return func(r *request) {
+		if !r.Command.AdminOnly {
+			next(r)
+			return
+		}
+		if !isAdmin(r.Msg) {
+			log.Printf("Отклонена админская команда /%s из чата %d", r.Name, r.Msg.Chat.ID)
+			b.reply(r.Msg, tr(r.Lang, "admin.only"))
+			return
+		}
+		log.Printf("Админская команда /%s из чата %d", r.Name, r.Msg.Chat.ID)
+		next(r)
+	}
+}
+
+// parseArgs разбирает аргументы команды; код приглашения из /redeem нужен и чатам без доступа
+func parseArgs(next handlerFunc) handlerFunc {
+	return func(r *request) {
+		r.Text = strings.TrimSpace(r.Msg.CommandArguments())
+		r.Args = strings.Fields(r.Text)
+		next(r)
+	}
+}
+
+// checkArgs отклоняет команды с лишними аргументами
+func (b *Bot) checkArgs(next handlerFunc) handlerFunc {
+	return func(r *request) {
+		if limit := r.Command.MaxArgs; limit != anyArgs && len(r.Args) > limit {
+			b.reply(r.Msg, tr(r.Lang, "args.too_many", r.Name, escapeHTML(tr(r.Lang, "cmd."+r.Name+".usage"))))
+			return
+		}
+		next(r)
+	}
+}
+
+// rateLimiter ограничивает частоту запросов по ключу (пользователю)
+type rateLimiter struct {
+	mu      sync.Mutex
+	rate    float64
+	burst   float64
+	buckets map[int64]*commandBucket
+}
+
+// commandBucket лимит команд одного пользователя
+type commandBucket struct {
+	*tokenBucket
+	// warned предупреждение о лимите уже отправлено, повторно не шлём, пока лимит не восстановится
+	warned bool
+}
+
+func newRateLimiter(burst int, refill time.Duration) *rateLimiter {
+	return &rateLimiter{
+		rate:    1 / refill.Seconds(),
+		burst:   float64(burst),
+		buckets: make(map[int64]*commandBucket),
+	}
+}
+
+// allow списывает запрос из лимита ключа. warn сообщает, что запрос отклонён впервые
+// с момента исчерпания лимита и пользователя стоит предупредить.
+func (l *rateLimiter) allow(key int64, now time.Time) (allowed, warn bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	bucket, ok := l.buckets[key]
+	if !ok {
+		l.prune(now)
+		bucket = &commandBucket{tokenBucket: newTokenBucket(l.rate, l.burst)}
+		bucket.last = now
+		l.buckets[key] = bucket
+	}
+	if bucket.wait(now) > 0 {
+		warn = !bucket.warned
+		bucket.warned = true
+		return false, warn
+	}
+	bucket.take()
+	bucket.warned = false
+	return true, false
+}
+
+// prune удаляет ключи, лимит которых давно полностью восстановился
+func (l *rateLimiter) prune(now time.Time) {
+	idle := time.Duration(l.burst / l.rate * float64(time.Second))
+	for key, bucket := range l.buckets {
+		if now.Sub(bucket.last) > idle {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// logPanic перехватывает панику в фоновой горутине и пишет её в лог
+func logPanic(where string) {
+	if err := recover(); err != nil {
+		log.Printf("Паника в %s: %v\n%s", where, err, debug.Stack())
+	}
+}
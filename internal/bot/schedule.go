@@ -0,0 +1,340 @@
+package bot
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultBroadcastInterval период рассылки для чатов без собственного расписания
+	defaultBroadcastInterval = 5 * time.Minute
+	// minBroadcastInterval чаще рассылать нет смысла: ставки обновляются раз в 2 минуты
+	minBroadcastInterval = 2 * time.Minute
+	// scheduleTick как часто цикл рассылки проверяет, каким чатам пора отправлять ставки
+	scheduleTick = time.Minute
+	// digestWindow дайджест, пропущенный дольше этого времени (например, бот был выключен), не отправляется
+	digestWindow = time.Hour
+)
+
+// parseClock разбирает время суток вида 23:00 и возвращает минуты от полуночи
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, errorf("err.bad_clock", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// formatClock форматирует минуты от полуночи как ЧЧ:ММ
+func formatClock(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+// minuteOfDay возвращает минуты от полуночи для момента t
+func minuteOfDay(t time.Time) int {
+	return t.Hour()*60 + t.Minute()
+}
+
+// inQuietHours проверяет, попадает ли момент в тихие часы чата; интервал может переходить через полночь
+func inQuietHours(settings ChatSettings, now time.Time) bool {
+	if settings.QuietStart == "" || settings.QuietEnd == "" {
+		return false
+	}
+	start, err := parseClock(settings.QuietStart)
+	if err != nil {
+		return false
+	}
+	end, err := parseClock(settings.QuietEnd)
+	if err != nil {
+		return false
+	}
+	current := minuteOfDay(now)
+	if start <= end {
+		return current >= start && current < end
+	}
+	return current >= start || current < end
+}
+
+// broadcastInterval возвращает период рассылки чата
+func broadcastInterval(settings ChatSettings) time.Duration {
+	if settings.EveryMinutes > 0 {
+		return time.Duration(settings.EveryMinutes) * time.Minute
+	}
+	return defaultBroadcastInterval
+}
+
+// lastDigestSlot возвращает последнее наступившее время дайджеста (в пределах digestWindow)
+func lastDigestSlot(settings ChatSettings, now time.Time) (time.Time, bool) {
+	var latest time.Time
+	for _, clock := range settings.Digest {
+		minutes, err := parseClock(clock)
+		if err != nil {
+			continue
+		}
+		// Время дайджеста сегодня или вчера, если сегодня оно ещё не наступило
+		slot := time.Date(now.Year(), now.Month(), now.Day(), minutes/60, minutes%60, 0, 0, now.Location())
+		if slot.After(now) {
+			slot = slot.AddDate(0, 0, -1)
+		}
+		if slot.After(latest) {
+			latest = slot
+		}
+	}
+	if latest.IsZero() || now.Sub(latest) > digestWindow {
+		return time.Time{}, false
+	}
+	return latest, true
+}
+
+// broadcastDue решает, пора ли отправить рассылку в чат. В режиме дайджеста ставки уходят
+// в заданное местное время, иначе — с периодом чата вне тихих часов.
+func broadcastDue(settings ChatSettings, lastSent, now time.Time) bool {
+	if len(settings.Digest) > 0 {
+		slot, ok := lastDigestSlot(settings, now)
+		return ok && lastSent.Before(slot)
+	}
+	if inQuietHours(settings, now) {
+		return false
+	}
+	return now.Sub(lastSent) >= broadcastInterval(settings)
+}
+
+// describeSchedule описывает расписание рассылки чата
+func describeSchedule(chatID int64, lang string) string {
+	settings := getChatSettings(chatID)
+	loc := chatLocation(chatID)
+	if len(settings.Digest) > 0 {
+		return tr(lang, "schedule.digest", strings.Join(settings.Digest, ", "), loc)
+	}
+	text := tr(lang, "schedule.every", formatDuration(broadcastInterval(settings), lang))
+	if settings.QuietStart != "" {
+		text += tr(lang, "schedule.quiet", settings.QuietStart, settings.QuietEnd, loc)
+	}
+	return text
+}
+
+// handleEvery показывает или задаёт период рассылки: /every 30m, /every default
+func (b *Bot) handleEvery(r *request) {
+	msg := r.Msg
+	lang := msgLang(msg)
+	args := r.Arg(0)
+	if args == "" {
+		b.reply(msg, tr(lang, "schedule.current", describeSchedule(msg.Chat.ID, lang))+tr(lang, "every.usage"))
+		return
+	}
+
+	if !b.canChangeSettings(msg) {
+		b.reply(msg, tr(lang, "schedule.admin_only"))
+		return
+	}
+
+	var minutes int
+	if !strings.EqualFold(args, "default") {
+		interval, err := time.ParseDuration(args)
+		if err != nil || interval < minBroadcastInterval {
+			b.reply(msg, tr(lang, "every.error", formatDuration(minBroadcastInterval, lang)))
+			return
+		}
+		minutes = int(interval / time.Minute)
+	}
+
+	updateChatSettings(msg.Chat.ID, func(settings *ChatSettings) {
+		settings.EveryMinutes = minutes
+		// Периодическая рассылка заменяет дайджест
+		settings.Digest = nil
+	})
+	b.reply(msg, tr(lang, "schedule.current", describeSchedule(msg.Chat.ID, lang)))
+}
+
+// handleQuiet задаёт тихие часы: /quiet 23:00-08:00 Europe/Kyiv, /quiet off
+func (b *Bot) handleQuiet(r *request) {
+	msg := r.Msg
+	lang := msgLang(msg)
+	args := r.Args
+	if len(args) == 0 {
+		b.reply(msg, tr(lang, "schedule.current", describeSchedule(msg.Chat.ID, lang))+tr(lang, "quiet.usage"))
+		return
+	}
+
+	if !b.canChangeSettings(msg) {
+		b.reply(msg, tr(lang, "schedule.admin_only"))
+		return
+	}
+
+	if strings.EqualFold(args[0], "off") {
+		updateChatSettings(msg.Chat.ID, func(settings *ChatSettings) {
+			settings.QuietStart, settings.QuietEnd = "", ""
+		})
+		b.reply(msg, tr(lang, "quiet.off", describeSchedule(msg.Chat.ID, lang)))
+		return
+	}
+
+	bounds := strings.Split(strings.ReplaceAll(args[0], "–", "-"), "-")
+	if len(bounds) != 2 || len(args) > 2 {
+		b.reply(msg, tr(lang, "quiet.error"))
+		return
+	}
+	start, err := parseClock(bounds[0])
+	if err != nil {
+		b.reply(msg, tr(lang, "error", escapeHTML(localize(lang, err))))
+		return
+	}
+	end, err := parseClock(bounds[1])
+	if err != nil {
+		b.reply(msg, tr(lang, "error", escapeHTML(localize(lang, err))))
+		return
+	}
+	if start == end {
+		b.reply(msg, tr(lang, "quiet.same"))
+		return
+	}
+
+	var timezone string
+	if len(args) == 2 {
+		if _, err := loadTimezone(args[1]); err != nil {
+			b.reply(msg, tr(lang, "tz.unknown", escapeHTML(args[1])))
+			return
+		}
+		timezone = args[1]
+	}
+
+	updateChatSettings(msg.Chat.ID, func(settings *ChatSettings) {
+		settings.QuietStart = formatClock(start)
+		settings.QuietEnd = formatClock(end)
+		if timezone != "" {
+			settings.Timezone = timezone
+		}
+	})
+	b.reply(msg, tr(lang, "schedule.current", describeSchedule(msg.Chat.ID, lang)))
+}
+
+// parseClockString приводит время суток к виду ЧЧ:ММ
+func parseClockString(value string) (string, error) {
+	minutes, err := parseClock(value)
+	if err != nil {
+		return "", err
+	}
+	return formatClock(minutes), nil
+}
+
+// handleDigest включает режим дайджеста: /digest 09:00 18:00, /digest off
+func (b *Bot) handleDigest(r *request) {
+	msg := r.Msg
+	lang := msgLang(msg)
+	args := strings.Fields(strings.ReplaceAll(r.Text, ",", " "))
+	if len(args) == 0 {
+		b.reply(msg, tr(lang, "schedule.current", describeSchedule(msg.Chat.ID, lang))+tr(lang, "digest.usage"))
+		return
+	}
+
+	if !b.canChangeSettings(msg) {
+		b.reply(msg, tr(lang, "schedule.admin_only"))
+		return
+	}
+
+	var digest []string
+	if !strings.EqualFold(args[0], "off") {
+		for _, arg := range args {
+			clock, err := parseClockString(arg)
+			if err != nil {
+				b.reply(msg, tr(lang, "error", escapeHTML(localize(lang, err))))
+				return
+			}
+			digest = append(digest, clock)
+		}
+		sort.Strings(digest)
+	}
+
+	updateChatSettings(msg.Chat.ID, func(settings *ChatSettings) {
+		settings.Digest = digest
+	})
+	b.reply(msg, tr(lang, "schedule.current", describeSchedule(msg.Chat.ID, lang)))
+}
+
+// lastSentFile время последних рассылок, рядом с settings.json: без него после перезапуска
+// всем чатам пора слать ставки, а дайджест последнего слота приходит повторно
+var lastSentFile = "last_sent.json"
+
+// loadLastSent восстанавливает время последних рассылок
+func (b *Bot) loadLastSent() {
+	data, err := os.ReadFile(lastSentFile)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			log.Printf("Не удалось прочитать %s: %v", lastSentFile, err)
+		}
+		return
+	}
+	var lastSent map[string]time.Time
+	if err := json.Unmarshal(data, &lastSent); err != nil {
+		log.Printf("Ошибка разбора %s: %v", lastSentFile, err)
+		return
+	}
+	// Файл с null даёт пустую карту, в которую нельзя записывать
+	if lastSent == nil {
+		lastSent = make(map[string]time.Time)
+	}
+	b.scheduleLock.Lock()
+	b.lastSent = lastSent
+	b.scheduleLock.Unlock()
+}
+
+// saveLastSent сохраняет время последних рассылок
+func (b *Bot) saveLastSent() {
+	b.scheduleLock.Lock()
+	data, err := json.MarshalIndent(b.lastSent, "", "  ")
+	b.scheduleLock.Unlock()
+	if err != nil {
+		log.Printf("Ошибка кодирования времени рассылок: %v", err)
+		return
+	}
+	if err := os.WriteFile(lastSentFile, append(data, '\n'), 0644); err != nil {
+		log.Printf("Ошибка записи %s: %v", lastSentFile, err)
+	}
+}
+
+// pruneLastSent забывает получателей, которых больше нет: отписанные чаты и удалённые цели
+func (b *Bot) pruneLastSent(recipients []recipient) {
+	keep := make(map[string]struct{}, len(recipients))
+	for _, to := range recipients {
+		keep[to.Key] = struct{}{}
+	}
+	b.scheduleLock.Lock()
+	for key := range b.lastSent {
+		if _, ok := keep[key]; !ok {
+			delete(b.lastSent, key)
+		}
+	}
+	b.scheduleLock.Unlock()
+}
+
+// markBroadcastSent запоминает время последней рассылки получателю
+func (b *Bot) markBroadcastSent(key string, at time.Time) {
+	b.scheduleLock.Lock()
+	b.lastSent[key] = at
+	b.scheduleLock.Unlock()
+}
+
+// dueRecipients отбирает получателей, которым по их расписанию пора отправить ставки.
+// Чатам с живым табло плановая рассылка не нужна: табло её заменяет.
+func (b *Bot) dueRecipients(recipients []recipient, now time.Time) []recipient {
+	b.scheduleLock.Lock()
+	defer b.scheduleLock.Unlock()
+
+	var due []recipient
+	for _, to := range recipients {
+		if to.Settings.Live {
+			continue
+		}
+		if broadcastDue(to.Settings, b.lastSent[to.Key], now.In(to.Loc)) {
+			due = append(due, to)
+		}
+	}
+	return due
+}
@@ -0,0 +1,41 @@
+package bot
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestLoadLastSentNull(t *testing.T) {
+	useTestSettings(t)
+	if err := os.WriteFile(lastSentFile, []byte("null\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	b := &Bot{lastSent: make(map[string]time.Time)}
+	b.loadLastSent()
+	b.markBroadcastSent("telegram:1", time.Now())
+	if _, ok := b.lastSent["telegram:1"]; !ok {
+		t.Error("время рассылки не записано")
+	}
+}
+
+func TestPruneLastSent(t *testing.T) {
+	now := time.Now()
+	b := &Bot{lastSent: map[string]time.Time{
+		"telegram:1":    now,
+		"telegram:2":    now,
+		"discord:old":   now,
+		"webhook:robot": now,
+	}}
+	b.pruneLastSent([]recipient{{Key: "telegram:1"}, {Key: "webhook:robot"}})
+
+	if len(b.lastSent) != 2 {
+		t.Errorf("должны остаться только текущие получатели, осталось %v", b.lastSent)
+	}
+	for _, key := range []string{"telegram:1", "webhook:robot"} {
+		if _, ok := b.lastSent[key]; !ok {
+			t.Errorf("получатель %s удалён по ошибке", key)
+		}
+	}
+}
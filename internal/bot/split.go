@@ -0,0 +1,150 @@
+package bot
+
+import (
+	"html"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+const (
+	// telegramTextLimit длина текста сообщения после разбора разметки, в кодовых единицах UTF-16, как считает Telegram
+	telegramTextLimit = 4096
+	// maxMessageParts длиннее этого сообщение отправляется одним документом, а не пачкой частей
+	maxMessageParts = 8
+)
+
+// htmlToken неделимый кусок HTML-разметки Telegram: тег, сущность или один символ
+type htmlToken struct {
+	text string
+	// width длина в тексте после разбора разметки, в единицах UTF-16; у тегов 0
+	width int
+	// tag имя тега; closing — закрывающий тег
+	tag     string
+	closing bool
+}
+
+// tokenizeHTML разбивает текст на теги, сущности и символы. Незакрытая «<» и «&» без «;»
+// считаются обычными символами, как и у Telegram после escapeHTML.
+func tokenizeHTML(text string) []htmlToken {
+	var tokens []htmlToken
+	for i := 0; i < len(text); {
+		switch text[i] {
+		case '<':
+			if end := strings.IndexByte(text[i:], '>'); end > 0 {
+				raw := text[i : i+end+1]
+				name := strings.TrimPrefix(raw[1:len(raw)-1], "/")
+				if cut := strings.IndexAny(name, " \t\n/"); cut >= 0 {
+					name = name[:cut]
+				}
+				tokens = append(tokens, htmlToken{text: raw, tag: strings.ToLower(name), closing: strings.HasPrefix(raw, "</")})
+				i += len(raw)
+				continue
+			}
+		case '&':
+			if end := strings.IndexByte(text[i:], ';'); end > 1 && end <= 10 {
+				raw := text[i : i+end+1]
+				if decoded := html.UnescapeString(raw); decoded != raw {
+					tokens = append(tokens, htmlToken{text: raw, width: utf16Len(decoded)})
+					i += len(raw)
+					continue
+				}
+			}
+		}
+		r, size := utf8.DecodeRuneInString(text[i:])
+		tokens = append(tokens, htmlToken{text: text[i : i+size], width: utf16.RuneLen(r)})
+		i += size
+	}
+	return tokens
+}
+
+// utf16Len длина строки в кодовых единицах UTF-16
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		n += utf16.RuneLen(r)
+	}
+	return n
+}
+
+// applyTag обновляет стек открытых тегов
+func applyTag(stack []htmlToken, token htmlToken) []htmlToken {
+	if token.tag == "" {
+		return stack
+	}
+	if !token.closing {
+		return append(stack, token)
+	}
+	for i := len(stack) - 1; i >= 0; i-- {
+		if stack[i].tag == token.tag {
+			return append(stack[:i:i], stack[i+1:]...)
+		}
+	}
+	return stack
+}
+
+// splitHTML разбивает HTML-текст на части не длиннее limit единиц UTF-16 видимого текста.
+// Разрыв делается по строке, лучше вне тегов; теги и сущности не разрезаются, а теги,
+// открытые на разрыве, закрываются в конце части и открываются заново в следующей.
+func splitHTML(text string, limit int) []string {
+	tokens := tokenizeHTML(text)
+	var parts []string
+	var stack []htmlToken
+	for start := 0; start < len(tokens); {
+		end, width := start, 0
+		lineBreak, outerBreak := -1, -1
+		open := append([]htmlToken(nil), stack...)
+		for end < len(tokens) && width+tokens[end].width <= limit {
+			width += tokens[end].width
+			open = applyTag(open, tokens[end])
+			end++
+			if tokens[end-1].text == "\n" {
+				lineBreak = end
+				if len(open) == 0 && width >= limit/2 {
+					outerBreak = end
+				}
+			}
+		}
+		if end < len(tokens) {
+			switch {
+			case outerBreak > 0:
+				end = outerBreak
+			case lineBreak > 0:
+				end = lineBreak
+			}
+		}
+		if end == start {
+			end++
+		}
+		// Закрывающие теги сразу за разрывом остаются в этой части, чтобы не было пустых элементов
+		for end < len(tokens) && tokens[end].closing {
+			end++
+		}
+
+		var part, visible strings.Builder
+		for _, tag := range stack {
+			part.WriteString(tag.text)
+		}
+		for _, token := range tokens[start:end] {
+			part.WriteString(token.text)
+			if token.tag == "" {
+				visible.WriteString(token.text)
+			}
+			stack = applyTag(stack, token)
+		}
+		for i := len(stack) - 1; i >= 0; i-- {
+			part.WriteString("</" + stack[i].tag + ">")
+		}
+		// Telegram не принимает сообщения из одних пробелов
+		if strings.TrimSpace(visible.String()) != "" {
+			parts = append(parts, part.String())
+		}
+		start = end
+	}
+	return parts
+}
+
+// plainText текст без разметки: для документа вместо длинного сообщения и внешних каналов
+func plainText(text string) string {
+	return html.UnescapeString(convertHTML(text, nil))
+}
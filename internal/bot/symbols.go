@@ -0,0 +1,222 @@
+package bot
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	exchanges "github.com/petrixs/cr-exchanges"
+)
+
+const defaultFundingInterval = 8 * time.Hour
+
+// fundingIntervals периодичность выплат фандинга на биржах, где она отличается от 8 часов.
+// Биржи не отдают период в ставках, поэтому он задаётся для биржи целиком: контракты с собственным
+// периодом (например, 4 часа на Binance и Bybit) пересчитываются на 8 часов так, будто платят раз в 8 часов.
+var fundingIntervals = map[string]time.Duration{
+	"hyperliquid": time.Hour,
+}
+
+// quoteSuffixes котируемые валюты и суффиксы контрактов, которые отбрасываются при нормализации
+var quoteSuffixes = []string{"SWAP", "PERP", "USDTM", "USDCM", "USDT", "USDC", "USD"}
+
+// symbolAliases биржевые обозначения монет, отличающиеся от общепринятых
+var symbolAliases = map[string]string{
+	"XBT": "BTC",
+}
+
+// normalizeSymbol приводит биржевой тикер к базовой монете:
+// BTCUSDT, BTC-USDT-SWAP, BTC_USDT, XBTUSDTM и BTC превращаются в BTC
+func normalizeSymbol(symbol string) string {
+	base, _ := symbolBase(symbol)
+	return base
+}
+
+// symbolBase возвращает базовую монету тикера и сколько монет в одной единице контракта:
+// у 1000PEPEUSDT и kPEPE это PEPE и 1000, у BTCUSDT — BTC и 1
+func symbolBase(symbol string) (string, float64) {
+	multiplier := 1.0
+	symbol = strings.TrimSpace(symbol)
+	// Hyperliquid обозначает контракты на 1000 монет строчной k: kPEPE
+	if len(symbol) > 1 && symbol[0] == 'k' && symbol[1] >= 'A' && symbol[1] <= 'Z' {
+		symbol = symbol[1:]
+		multiplier = 1000
+	}
+
+	s := strings.ToUpper(symbol)
+	s = strings.NewReplacer("-", "", "_", "", "/", "", ":", "").Replace(s)
+	for trimmed := true; trimmed; {
+		trimmed = false
+		for _, suffix := range quoteSuffixes {
+			if len(s) > len(suffix) && strings.HasSuffix(s, suffix) {
+				s = strings.TrimSuffix(s, suffix)
+				trimmed = true
+			}
+		}
+	}
+	for _, prefix := range []struct {
+		text       string
+		multiplier float64
+	}{{"1000000", 1e6}, {"1000", 1000}} {
+		if len(s) > len(prefix.text) && strings.HasPrefix(s, prefix.text) {
+			s = strings.TrimPrefix(s, prefix.text)
+			multiplier *= prefix.multiplier
+			break
+		}
+	}
+	if alias, ok := symbolAliases[s]; ok {
+		s = alias
+	}
+	return s, multiplier
+}
+
+// fundingInterval возвращает периодичность выплат фандинга биржи
+func fundingInterval(exchangeName string) time.Duration {
+	if interval, ok := fundingIntervals[strings.ToLower(exchangeName)]; ok {
+		return interval
+	}
+	return defaultFundingInterval
+}
+
+// normalizedRate8h пересчитывает ставку на 8-часовой период для сравнения бирж
+func normalizedRate8h(exchangeName string, rate float64) float64 {
+	return rate * float64(8*time.Hour) / float64(fundingInterval(exchangeName))
+}
+
+// venueRate ставка контракта на конкретной бирже
+type venueRate struct {
+	Exchange string
+	Rate     exchanges.FundingRate
+	Rate8h   float64
+}
+
+// findSymbolRates собирает ставки монеты со всех бирж, отсортированные по 8-часовой ставке
+func findSymbolRates(rates map[string][]exchanges.FundingRate, symbol string) []venueRate {
+	base := normalizeSymbol(symbol)
+	var found []venueRate
+	for exchangeName, exchangeRates := range rates {
+		for _, rate := range exchangeRates {
+			if normalizeSymbol(rate.Symbol) != base {
+				continue
+			}
+			found = append(found, venueRate{
+				Exchange: exchangeName,
+				Rate:     rate,
+				Rate8h:   normalizedRate8h(exchangeName, rate.Rate),
+			})
+		}
+	}
+	sort.Slice(found, func(i, j int) bool {
+		return found[i].Rate8h > found[j].Rate8h
+	})
+	return found
+}
+
+// groupBySymbol раскладывает ставки всех бирж по монетам; ставки монеты отсортированы как в findSymbolRates
+func groupBySymbol(rates map[string][]exchanges.FundingRate) map[string][]venueRate {
+	bases := make(map[string]string)
+	grouped := make(map[string][]venueRate)
+	for exchangeName, exchangeRates := range rates {
+		for _, rate := range exchangeRates {
+			base, ok := bases[rate.Symbol]
+			if !ok {
+				base = normalizeSymbol(rate.Symbol)
+				bases[rate.Symbol] = base
+			}
+			grouped[base] = append(grouped[base], venueRate{
+				Exchange: exchangeName,
+				Rate:     rate,
+				Rate8h:   normalizedRate8h(exchangeName, rate.Rate),
+			})
+		}
+	}
+	for _, found := range grouped {
+		sort.Slice(found, func(i, j int) bool {
+			return found[i].Rate8h > found[j].Rate8h
+		})
+	}
+	return grouped
+}
+
+// bestPair лучшая пара для арбитража фандинга из ставок монеты, отсортированных по убыванию 8-часовой ставки:
+// лонг там, где ставка минимальна (лонги платят меньше всего или получают), шорт там, где ставка максимальна.
+// Биржи в паре разные. Лучшая пара всегда содержит максимум или минимум: если они на одной бирже,
+// сравниваются максимум с минимумом других бирж и минимум с максимумом других бирж.
+func bestPair(found []venueRate) (long, short venueRate, ok bool) {
+	if len(found) < 2 {
+		return long, short, false
+	}
+	first, last := found[0], found[len(found)-1]
+	if first.Exchange != last.Exchange {
+		return last, first, true
+	}
+	for i := len(found) - 1; i > 0; i-- {
+		if found[i].Exchange != first.Exchange {
+			long, short, ok = found[i], first, true
+			break
+		}
+	}
+	for i := 0; i < len(found)-1; i++ {
+		if found[i].Exchange != last.Exchange {
+			if !ok || found[i].Rate8h-last.Rate8h > short.Rate8h-long.Rate8h {
+				long, short, ok = last, found[i], true
+			}
+			break
+		}
+	}
+	return long, short, ok
+}
+
+// handleSymbol показывает ставки одной монеты на всех биржах: /symbol BTC
+func (b *Bot) handleSymbol(r *request) {
+	msg := r.Msg
+	lang := msgLang(msg)
+	args := r.Arg(0)
+	if args == "" {
+		b.reply(msg, tr(lang, "symbol.usage"))
+		return
+	}
+
+	base := normalizeSymbol(args)
+	found := findSymbolRates(b.cache.GetAllRates(), args)
+	if len(found) == 0 {
+		b.reply(msg, tr(lang, "symbol.not_found", escapeHTML(base)))
+		return
+	}
+
+	// Если на бирже несколько контрактов монеты, показываем тикер рядом с биржей
+	perExchange := make(map[string]int)
+	for _, r := range found {
+		perExchange[r.Exchange]++
+	}
+
+	loc, now := chatLocation(msg.Chat.ID), time.Now()
+	lines := []string{fmt.Sprintf("%-12s %9s %9s  %-13s %s", tr(lang, "col.exchange"), tr(lang, "col.rate"),
+		tr(lang, "col.rate8h"), tr(lang, "col.payment"), tr(lang, "col.volume"))}
+	for _, r := range found {
+		name := r.Exchange
+		if perExchange[r.Exchange] > 1 {
+			name += " " + r.Rate.Symbol
+		}
+		payment := "—"
+		if t, ok := nextFundingTime(r.Rate); ok {
+			payment = formatFundingClock(t, loc, now, lang)
+		}
+		volume := formatVolume(r.Rate)
+		if volume == "" {
+			volume = "—"
+		}
+		lines = append(lines, fmt.Sprintf("%-12s %+8.4f%% %+8.4f%%  %-13s %s",
+			name, r.Rate.Rate*100, r.Rate8h*100, payment, volume))
+	}
+
+	text := trn(lang, "symbol.title", len(perExchange), escapeHTML(base), len(perExchange)) +
+		"<pre>" + escapeHTML(strings.Join(lines, "\n")) + "</pre>"
+
+	if long, short, ok := bestPair(found); ok {
+		text += tr(lang, "symbol.best_pair", long.Exchange, short.Exchange, (short.Rate8h-long.Rate8h)*100)
+	}
+
+	b.reply(msg, text)
+}
@@ -0,0 +1,31 @@
+package bot
+
+import "testing"
+
+func TestBestPairSkipsSameExchange(t *testing.T) {
+	// Максимум и минимум на одной бирже: лучшая пара — максимум Binance против минимума OKX
+	found := []venueRate{
+		{Exchange: "Binance", Rate8h: 0.003},
+		{Exchange: "Bybit", Rate8h: 0.001},
+		{Exchange: "OKX", Rate8h: -0.002},
+		{Exchange: "Binance", Rate8h: -0.004},
+	}
+	long, short, ok := bestPair(found)
+	if !ok {
+		t.Fatal("пара на разных биржах существует")
+	}
+	if short.Exchange != "Binance" || short.Rate8h != 0.003 || long.Exchange != "OKX" {
+		t.Errorf("ожидалась пара шорт Binance / лонг OKX, получено шорт %s %v / лонг %s %v",
+			short.Exchange, short.Rate8h, long.Exchange, long.Rate8h)
+	}
+}
+
+func TestBestPairSingleExchange(t *testing.T) {
+	found := []venueRate{
+		{Exchange: "Binance", Rate8h: 0.003},
+		{Exchange: "Binance", Rate8h: -0.004},
+	}
+	if _, _, ok := bestPair(found); ok {
+		t.Error("на одной бирже пары быть не может")
+	}
+}
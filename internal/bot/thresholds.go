@@ -0,0 +1,126 @@
+package bot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// rateThresholds пороги чата: отдельно для положительных и отрицательных ставок
+type rateThresholds struct {
+	// Positive минимальная положительная ставка (доля, 0.001 = 0.1%)
+	Positive float64
+	// Negative минимальный модуль отрицательной ставки
+	Negative float64
+	// Direction показывать только положительные или только отрицательные ставки
+	Direction string
+}
+
+// getRateThresholds возвращает пороги чата. Положительный порог хранится в thresholds,
+// отрицательный — в настройках чата; если он не задан, действует положительный.
+func getRateThresholds(chatID int64) rateThresholds {
+	positive, _ := getUserThreshold(chatID)
+	settings := getChatSettings(chatID)
+	negative := settings.NegativeThreshold
+	if negative == 0 {
+		negative = positive
+	}
+	return rateThresholds{
+		Positive:  positive,
+		Negative:  negative,
+		Direction: settings.Direction,
+	}
+}
+
+// pass проверяет, проходит ли ставка пороги и фильтр направления
+func (t rateThresholds) pass(rate float64) bool {
+	if t.Direction == directionPositive && rate <= 0 || t.Direction == directionNegative && rate >= 0 {
+		return false
+	}
+	if rate < 0 {
+		return -rate >= t.Negative
+	}
+	return rate >= t.Positive
+}
+
+// String описывает пороги для логов
+func (t rateThresholds) String() string {
+	return t.describe(langRU)
+}
+
+// describe описывает пороги для ответов пользователю
+func (t rateThresholds) describe(lang string) string {
+	var parts []string
+	if t.Direction != directionNegative {
+		parts = append(parts, fmt.Sprintf("+%.3f%%", t.Positive*100))
+	}
+	if t.Direction != directionPositive {
+		parts = append(parts, fmt.Sprintf("−%.3f%%", t.Negative*100))
+	}
+	text := strings.Join(parts, " / ")
+	switch t.Direction {
+	case directionPositive:
+		text += tr(lang, "threshold.only_positive")
+	case directionNegative:
+		text += tr(lang, "threshold.only_negative")
+	}
+	return text
+}
+
+// parseThresholdValue разбирает значение порога в процентах, как пороги показываются в боте:
+// 0.05 и 0.05% — это 0.05%. Знак % необязателен.
+func parseThresholdValue(value string) (float64, error) {
+	value = strings.TrimSuffix(value, "%")
+
+	threshold, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, err
+	}
+	if threshold <= 0 {
+		return 0, fmt.Errorf("порог должен быть положительным числом")
+	}
+	return threshold / 100, nil
+}
+
+// thresholdUpdate изменения, запрошенные командой /threshold
+type thresholdUpdate struct {
+	Both         float64
+	Positive     float64
+	Negative     float64
+	Direction    string
+	SetDirection bool
+}
+
+// parseThresholdArgs разбирает аргументы /threshold: 0.1, +0.05 -0.2, positive, negative, both
+func parseThresholdArgs(args []string) (thresholdUpdate, error) {
+	var update thresholdUpdate
+	for _, token := range args {
+		switch strings.ToLower(token) {
+		case "positive", "pos":
+			update.Direction, update.SetDirection = directionPositive, true
+			continue
+		case "negative", "neg":
+			update.Direction, update.SetDirection = directionNegative, true
+			continue
+		case "both", "all":
+			update.Direction, update.SetDirection = directionAny, true
+			continue
+		}
+
+		target := &update.Both
+		switch {
+		case strings.HasPrefix(token, "+"):
+			target, token = &update.Positive, strings.TrimPrefix(token, "+")
+		case strings.HasPrefix(token, "-"):
+			target, token = &update.Negative, strings.TrimPrefix(token, "-")
+		case strings.HasPrefix(token, "−"):
+			target, token = &update.Negative, strings.TrimPrefix(token, "−")
+		}
+		value, err := parseThresholdValue(token)
+		if err != nil {
+			return update, errorf("err.bad_threshold", token)
+		}
+		*target = value
+	}
+	return update, nil
+}
@@ -0,0 +1,140 @@
+package bot
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// loadTimezone разбирает часовой пояс: имя из базы IANA (Europe/Kyiv) или смещение UTC+3, UTC-5:30
+func loadTimezone(name string) (*time.Location, error) {
+	upper := strings.ToUpper(name)
+	if offset, ok := strings.CutPrefix(upper, "UTC"); ok && offset != "" {
+		return parseUTCOffset(name, offset)
+	}
+	if offset, ok := strings.CutPrefix(upper, "GMT"); ok && offset != "" {
+		return parseUTCOffset(name, offset)
+	}
+	return time.LoadLocation(name)
+}
+
+// parseUTCOffset разбирает смещение вида +3, -5:30
+func parseUTCOffset(name, offset string) (*time.Location, error) {
+	sign := 1
+	switch offset[0] {
+	case '+':
+	case '-':
+		sign = -1
+	default:
+		return nil, fmt.Errorf("некорректное смещение %q", name)
+	}
+	hoursText, minutesText, _ := strings.Cut(offset[1:], ":")
+	hours, err := strconv.Atoi(hoursText)
+	if err != nil || hours > 14 {
+		return nil, fmt.Errorf("некорректное смещение %q", name)
+	}
+	var minutes int
+	if minutesText != "" {
+		if minutes, err = strconv.Atoi(minutesText); err != nil || minutes >= 60 {
+			return nil, fmt.Errorf("некорректное смещение %q", name)
+		}
+	}
+	return time.FixedZone(strings.ToUpper(name), sign*(hours*3600+minutes*60)), nil
+}
+
+// chatLocation возвращает часовой пояс чата: из настроек чата, иначе TIMEZONE, иначе UTC
+func chatLocation(chatID int64) *time.Location {
+	if name := getChatSettings(chatID).Timezone; name != "" {
+		if loc, err := loadTimezone(name); err == nil {
+			return loc
+		}
+	}
+	return defaultLocation()
+}
+
+// defaultLocation часовой пояс по умолчанию из TIMEZONE, иначе UTC
+func defaultLocation() *time.Location {
+	if name := os.Getenv("TIMEZONE"); name != "" {
+		if loc, err := loadTimezone(name); err == nil {
+			return loc
+		}
+	}
+	return time.UTC
+}
+
+// formatDuration форматирует длительность с точностью до минут: 1ч 12м, 45м, 2д 3ч, <1м
+func formatDuration(d time.Duration, lang string) string {
+	day, hour, minute := tr(lang, "unit.day"), tr(lang, "unit.hour"), tr(lang, "unit.minute")
+	if d < time.Minute {
+		return "<1" + minute
+	}
+	d = d.Truncate(time.Minute)
+	days := int(d.Hours()) / 24
+	hours := int(d.Hours()) % 24
+	minutes := int(d.Minutes()) % 60
+	switch {
+	case days > 0 && hours > 0:
+		return fmt.Sprintf("%d%s %d%s", days, day, hours, hour)
+	case days > 0:
+		return fmt.Sprintf("%d%s", days, day)
+	case hours > 0 && minutes > 0:
+		return fmt.Sprintf("%d%s %d%s", hours, hour, minutes, minute)
+	case hours > 0:
+		return fmt.Sprintf("%d%s", hours, hour)
+	default:
+		return fmt.Sprintf("%d%s", minutes, minute)
+	}
+}
+
+// formatFundingTime форматирует время выплаты в поясе чата с обратным отсчётом:
+// 18.10 19:00 EEST, через 1ч 12м
+func formatFundingTime(t time.Time, loc *time.Location, now time.Time, lang string) string {
+	text := t.In(loc).Format("02.01 15:04 MST")
+	if left := t.Sub(now); left > 0 {
+		text += ", " + tr(lang, "countdown.in", formatDuration(left, lang))
+	}
+	return text
+}
+
+// formatFundingClock короткий вариант для таблиц: 19:00 (1ч 12м)
+func formatFundingClock(t time.Time, loc *time.Location, now time.Time, lang string) string {
+	text := t.In(loc).Format("15:04")
+	if left := t.Sub(now); left > 0 {
+		text += " (" + formatDuration(left, lang) + ")"
+	}
+	return text
+}
+
+// handleTimezone показывает или задаёт часовой пояс чата: /tz Europe/Kyiv, /tz UTC+3, /tz default
+func (b *Bot) handleTimezone(r *request) {
+	msg := r.Msg
+	lang := msgLang(msg)
+	args := r.Arg(0)
+	if args == "" {
+		loc := chatLocation(msg.Chat.ID)
+		b.reply(msg, tr(lang, "tz.current", loc, time.Now().In(loc).Format("15:04")))
+		return
+	}
+
+	if !b.canChangeSettings(msg) {
+		b.reply(msg, tr(lang, "tz.admin_only"))
+		return
+	}
+
+	var timezone string
+	if !strings.EqualFold(args, "default") {
+		if _, err := loadTimezone(args); err != nil {
+			b.reply(msg, tr(lang, "tz.unknown", escapeHTML(args)))
+			return
+		}
+		timezone = args
+	}
+
+	updateChatSettings(msg.Chat.ID, func(settings *ChatSettings) {
+		settings.Timezone = timezone
+	})
+	loc := chatLocation(msg.Chat.ID)
+	b.reply(msg, tr(lang, "tz.set", loc, time.Now().In(loc).Format("15:04")))
+}
@@ -0,0 +1,113 @@
+package bot
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	exchanges "github.com/petrixs/cr-exchanges"
+)
+
+const (
+	defaultTopLimit = 10
+	maxTopLimit     = 50
+)
+
+// topRates объединяет ставки всех бирж, отбирает их фильтром и ранжирует по 8-часовой ставке:
+// по модулю, а при фильтре по направлению — от самой большой положительной или самой отрицательной
+func topRates(rates map[string][]exchanges.FundingRate, filter rateFilter) []venueRate {
+	prices := buildPriceIndex(rates)
+	var merged []venueRate
+	for exchangeName, exchangeRates := range rates {
+		if !filter.matchExchange(exchangeName) {
+			continue
+		}
+		for _, rate := range exchangeRates {
+			if !filter.match(rate, prices) {
+				continue
+			}
+			merged = append(merged, venueRate{
+				Exchange: exchangeName,
+				Rate:     rate,
+				Rate8h:   normalizedRate8h(exchangeName, rate.Rate),
+			})
+		}
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		switch filter.Direction {
+		case directionPositive:
+			return merged[i].Rate8h > merged[j].Rate8h
+		case directionNegative:
+			return merged[i].Rate8h < merged[j].Rate8h
+		default:
+			return math.Abs(merged[i].Rate8h) > math.Abs(merged[j].Rate8h)
+		}
+	})
+
+	limit := filter.Limit
+	if limit == 0 {
+		limit = defaultTopLimit
+	}
+	if limit > maxTopLimit {
+		limit = maxTopLimit
+	}
+	if len(merged) > limit {
+		merged = merged[:limit]
+	}
+	return merged
+}
+
+// handleTop показывает самые экстремальные ставки со всех бирж одним списком:
+// /top [n] [positive|negative] [minvol=5M] [exchange=binance,bybit]
+func (b *Bot) handleTop(r *request) {
+	msg := r.Msg
+	lang := msgLang(msg)
+	filter, err := parseRateFilter(r.Args)
+	if err == nil {
+		err = filter.validateExchanges(b.exchanges)
+	}
+	if err != nil {
+		b.reply(msg, tr(lang, "top.error", escapeHTML(localize(lang, err))))
+		return
+	}
+
+	rates := b.cache.GetAllRates()
+	if len(rates) == 0 {
+		b.reply(msg, tr(lang, "rates.empty"))
+		return
+	}
+
+	// Без явного minvol действует фильтр ликвидности чата
+	if filter.MinVolume == 0 {
+		filter.MinVolume = getMinVolume(msg.Chat.ID)
+	}
+	top := topRates(rates, filter)
+	if len(top) == 0 {
+		b.reply(msg, tr(lang, "top.no_match"))
+		return
+	}
+
+	loc, now := chatLocation(msg.Chat.ID), time.Now()
+	lines := make([]string, 0, len(top))
+	for i, r := range top {
+		payment := "—"
+		if t, ok := nextFundingTime(r.Rate); ok {
+			payment = formatFundingClock(t, loc, now, lang)
+		}
+		volume := formatVolume(r.Rate)
+		if volume == "" {
+			volume = "—"
+		}
+		lines = append(lines, fmt.Sprintf("%2d. %-11s %-14s %+8.4f%% (%s %+8.4f%%) %8s  %s",
+			i+1, r.Exchange, r.Rate.Symbol, r.Rate.Rate*100, tr(lang, "col.rate8h"), r.Rate8h*100, volume, payment))
+	}
+
+	header := tr(lang, "top.header", len(top))
+	if description := filter.describe(lang); description != "" {
+		header += " (" + escapeHTML(description) + ")"
+	}
+	b.reply(msg, header+"\n<pre>"+escapeHTML(strings.Join(lines, "\n"))+"</pre>")
+}
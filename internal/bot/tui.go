@@ -0,0 +1,621 @@
+package bot
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"unicode/utf8"
+
+	exchanges "github.com/petrixs/cr-exchanges"
+)
+
+// Панель ставок в терминале: funding-screener tui. Рисуется ANSI-последовательностями,
+// а посимвольный ввод включается через stty, поэтому внешние зависимости не нужны.
+
+const (
+	// dashboardRedraw период перерисовки: обратный отсчёт до выплат идёт по секундам
+	dashboardRedraw = time.Second
+	// dashboardCellWidth ширина ячейки биржи в строке состояния бирж
+	dashboardCellWidth = 26
+	// dashboardErrorWidth сколько символов ошибки биржи показывать
+	dashboardErrorWidth = 60
+)
+
+// Дополнительные режимы сортировки панели; остальные — те же, что в /rates
+const (
+	sortBySymbol   = "symbol"
+	sortByExchange = "exchange"
+)
+
+// dashboardSorts режимы сортировки в порядке переключения клавишей s
+var dashboardSorts = []string{sortByAbsRate, sortByPositive, sortByNegative, sortByVolume, sortByNextFunding, sortBySymbol, sortByExchange}
+
+// ANSI-последовательности
+const (
+	ansiReset     = "\x1b[0m"
+	ansiBold      = "\x1b[1m"
+	ansiDim       = "\x1b[2m"
+	ansiReverse   = "\x1b[7m"
+	ansiRed       = "\x1b[31m"
+	ansiGreen     = "\x1b[32m"
+	ansiYellow    = "\x1b[33m"
+	ansiClearLine = "\x1b[K"
+	ansiClearDown = "\x1b[J"
+	ansiHome      = "\x1b[H"
+	ansiEnter     = "\x1b[?1049h\x1b[?25l"
+	ansiLeave     = "\x1b[?25h\x1b[?1049l"
+)
+
+// dashboardColumn столбец таблицы: ширина и выравнивание
+type dashboardColumn struct {
+	Title string
+	Width int
+	Right bool
+}
+
+// dashboard состояние панели. Ставки обновляются в фоне, а рисует и обрабатывает клавиши
+// только основной цикл run, поэтому блокировка нужна лишь для полей, которые пишет обновление.
+type dashboard struct {
+	b    *Bot
+	tty  *os.File
+	out  io.Writer
+	lang string
+	loc  *time.Location
+
+	sort     string
+	exchange int // индекс в b.exchanges, -1 — все биржи
+	filter   string
+	editing  bool
+	offset   int
+
+	statusLock sync.Mutex
+	updating   bool
+	lastUpdate time.Time
+}
+
+// Dashboard показывает живую таблицу ставок всех бирж в терминале tty, пока не нажата q.
+// Ставки опрашиваются с тем же периодом, что и в боте; Telegram и RabbitMQ не нужны.
+func Dashboard(exs []exchanges.Exchange, tty *os.File) error {
+	restore, err := enterRawMode(tty)
+	if err != nil {
+		return fmt.Errorf("нужен терминал: %w", err)
+	}
+	defer restore()
+
+	d := &dashboard{
+		// История не пишется: её ведёт запущенный бот, а панель лишь смотрит на ставки
+		b:        newHeadlessBot(exs, nil),
+		tty:      tty,
+		out:      tty,
+		lang:     defaultLang(),
+		loc:      defaultLocation(),
+		sort:     sortByAbsRate,
+		exchange: -1,
+	}
+	io.WriteString(d.out, ansiEnter)
+	defer io.WriteString(d.out, ansiLeave)
+	d.run()
+	return nil
+}
+
+// run основной цикл: клавиши, перерисовка и фоновое обновление ставок
+func (d *dashboard) run() {
+	keys := make(chan string, 16)
+	go readKeys(d.tty, keys)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	updated := make(chan struct{}, 1)
+	force := make(chan struct{}, 1)
+	done := make(chan struct{})
+	defer close(done)
+	go d.refreshLoop(updated, force, done)
+
+	ticker := time.NewTicker(dashboardRedraw)
+	defer ticker.Stop()
+
+	for {
+		d.render()
+		select {
+		case key, ok := <-keys:
+			if !ok || !d.handleKey(key, force) {
+				return
+			}
+		case <-updated:
+		case <-ticker.C:
+		case <-signals:
+			return
+		}
+	}
+}
+
+// refreshLoop обновляет ставки раз в ratesUpdateInterval или по нажатию u
+func (d *dashboard) refreshLoop(updated, force, done chan struct{}) {
+	ticker := time.NewTicker(ratesUpdateInterval)
+	defer ticker.Stop()
+
+	for {
+		d.setUpdating(true)
+		notify(updated)
+		d.b.updateAllRates()
+		d.setUpdating(false)
+		notify(updated)
+		ticker.Reset(ratesUpdateInterval)
+
+		select {
+		case <-ticker.C:
+		case <-force:
+		case <-done:
+			return
+		}
+	}
+}
+
+func (d *dashboard) setUpdating(updating bool) {
+	d.statusLock.Lock()
+	defer d.statusLock.Unlock()
+	d.updating = updating
+	if !updating {
+		d.lastUpdate = time.Now()
+	}
+}
+
+// notify неблокирующая отправка в канал-сигнал
+func notify(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+// handleKey обрабатывает клавишу; false — выйти из панели
+func (d *dashboard) handleKey(key string, force chan struct{}) bool {
+	if d.editing {
+		switch key {
+		case "enter":
+			d.editing = false
+		case "esc":
+			d.editing = false
+			d.filter = ""
+		case "backspace":
+			if d.filter != "" {
+				_, size := utf8.DecodeLastRuneInString(d.filter)
+				d.filter = d.filter[:len(d.filter)-size]
+			}
+		default:
+			if utf8.RuneCountInString(key) == 1 {
+				d.filter += key
+			}
+		}
+		d.offset = 0
+		return true
+	}
+
+	_, height := terminalSize(d.tty)
+	page := height / 2
+	switch key {
+	case "q", "Q":
+		return false
+	case "s":
+		d.sort = dashboardSorts[(indexOf(dashboardSorts, d.sort)+1)%len(dashboardSorts)]
+		d.offset = 0
+	case "S":
+		d.sort = dashboardSorts[(indexOf(dashboardSorts, d.sort)+len(dashboardSorts)-1)%len(dashboardSorts)]
+		d.offset = 0
+	case "e":
+		d.exchange++
+		if d.exchange >= len(d.b.exchanges) {
+			d.exchange = -1
+		}
+		d.offset = 0
+	case "/":
+		d.editing = true
+	case "esc":
+		d.filter = ""
+		d.exchange = -1
+		d.offset = 0
+	case "u":
+		notify(force)
+	case "up", "k":
+		d.offset--
+	case "down", "j":
+		d.offset++
+	case "pgup":
+		d.offset -= page
+	case "pgdn", " ":
+		d.offset += page
+	case "home", "g":
+		d.offset = 0
+	case "end", "G":
+		d.offset = math.MaxInt32
+	}
+	return true
+}
+
+func indexOf(values []string, value string) int {
+	for i, v := range values {
+		if v == value {
+			return i
+		}
+	}
+	return 0
+}
+
+// rows объединяет ставки бирж, отбирает их фильтром панели и сортирует
+func (d *dashboard) rows(rates map[string][]exchanges.FundingRate, prices priceIndex) []venueRate {
+	terms := strings.Fields(strings.ToLower(d.filter))
+	var rows []venueRate
+	// Биржи обходятся в постоянном порядке, чтобы строки с равным ключом сортировки не прыгали между перерисовками
+	for i, ex := range d.b.exchanges {
+		if d.exchange >= 0 && i != d.exchange {
+			continue
+		}
+		exchangeName := ex.GetName()
+		for _, rate := range rates[exchangeName] {
+			if !matchTerms(terms, exchangeName, rate.Symbol) {
+				continue
+			}
+			rows = append(rows, venueRate{
+				Exchange: exchangeName,
+				Rate:     rate,
+				Rate8h:   normalizedRate8h(exchangeName, rate.Rate),
+			})
+		}
+	}
+	sortDashboardRows(rows, d.sort, prices)
+	return rows
+}
+
+// matchTerms каждое слово фильтра должно встречаться в названии биржи или тикере
+func matchTerms(terms []string, exchangeName, symbol string) bool {
+	haystack := strings.ToLower(exchangeName + " " + symbol + " " + normalizeSymbol(symbol))
+	for _, term := range terms {
+		if !strings.Contains(haystack, term) {
+			return false
+		}
+	}
+	return true
+}
+
+// sortDashboardRows сортирует строки панели по 8-часовой ставке, объёму, времени выплаты, тикеру или бирже
+func sortDashboardRows(rows []venueRate, mode string, prices priceIndex) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		switch mode {
+		case sortByPositive:
+			return a.Rate8h > b.Rate8h
+		case sortByNegative:
+			return a.Rate8h < b.Rate8h
+		case sortByVolume:
+			return prices.usdVolume(a.Rate) > prices.usdVolume(b.Rate)
+		case sortByNextFunding:
+			ta, okA := nextFundingTime(a.Rate)
+			tb, okB := nextFundingTime(b.Rate)
+			if okA != okB {
+				return okA
+			}
+			return ta.Before(tb)
+		case sortBySymbol:
+			if a.Rate.Symbol != b.Rate.Symbol {
+				return a.Rate.Symbol < b.Rate.Symbol
+			}
+			return a.Exchange < b.Exchange
+		case sortByExchange:
+			if a.Exchange != b.Exchange {
+				return a.Exchange < b.Exchange
+			}
+			return math.Abs(a.Rate8h) > math.Abs(b.Rate8h)
+		default:
+			return math.Abs(a.Rate8h) > math.Abs(b.Rate8h)
+		}
+	})
+}
+
+// render перерисовывает экран целиком; строки дописываются поверх старых, без мерцания
+func (d *dashboard) render() {
+	width, height := terminalSize(d.tty)
+	now := time.Now()
+	lang := d.lang
+
+	rates := d.b.cache.GetAllRates()
+	prices := buildPriceIndex(rates)
+	rows := d.rows(rates, prices)
+
+	var screen strings.Builder
+	screen.WriteString(ansiHome)
+	used := 0
+	line := func(text string) {
+		screen.WriteString(text + ansiReset + ansiClearLine + "\r\n")
+		used++
+	}
+
+	line(ansiBold + truncate("Funding Screener · "+d.updateStatus(len(rows), now), width))
+	line(truncate(d.viewStatus(), width))
+	for _, healthLine := range d.healthLines(width, now) {
+		line(healthLine)
+	}
+
+	columns := []dashboardColumn{
+		{tr(lang, "col.exchange"), 12, false},
+		{tr(lang, "col.symbol"), 18, false},
+		{tr(lang, "col.rate"), 10, true},
+		{tr(lang, "col.rate8h"), 10, true},
+		{tr(lang, "col.payment"), 20, false},
+		{tr(lang, "col.volume"), 10, true},
+	}
+	titles := make([]string, len(columns))
+	for i, c := range columns {
+		titles[i] = c.Title
+	}
+	line(ansiReverse + padRight(formatCells(columns, titles, nil, width), width))
+
+	// Последняя строка экрана — подсказка по клавишам
+	visible := height - used - 1
+	if visible < 1 {
+		visible = 1
+	}
+	maxOffset := len(rows) - visible
+	if maxOffset < 0 {
+		maxOffset = 0
+	}
+	d.offset = min(max(d.offset, 0), maxOffset)
+
+	if len(rows) == 0 {
+		line(ansiDim + truncate(tr(lang, "tui.empty"), width))
+		visible--
+	}
+	end := min(d.offset+visible, len(rows))
+	for _, row := range rows[d.offset:end] {
+		line(d.formatRow(columns, row, now, width))
+	}
+	for i := end - d.offset; i < visible; i++ {
+		line("")
+	}
+
+	footer := tr(lang, "tui.help")
+	if d.editing {
+		footer = tr(lang, "tui.filter_prompt", d.filter)
+	} else if len(rows) > 0 {
+		footer = tr(lang, "tui.shown", d.offset+1, end, len(rows)) + " · " + footer
+	}
+	screen.WriteString(ansiReverse + padRight(truncate(footer, width), width) + ansiReset + ansiClearDown)
+
+	io.WriteString(d.out, screen.String())
+}
+
+// updateStatus количество ставок и время последнего и следующего обновления
+func (d *dashboard) updateStatus(count int, now time.Time) string {
+	d.statusLock.Lock()
+	updating, lastUpdate := d.updating, d.lastUpdate
+	d.statusLock.Unlock()
+
+	switch {
+	case lastUpdate.IsZero():
+		return tr(d.lang, "tui.loading")
+	case updating:
+		return trn(d.lang, "tui.updating", count, count, lastUpdate.In(d.loc).Format("15:04:05"))
+	default:
+		next := lastUpdate.Add(ratesUpdateInterval).Sub(now)
+		return trn(d.lang, "tui.updated", count, count, lastUpdate.In(d.loc).Format("15:04:05"), formatDuration(max(next, 0), d.lang))
+	}
+}
+
+// viewStatus текущие сортировка, биржа и фильтр
+func (d *dashboard) viewStatus() string {
+	exchangeName := tr(d.lang, "tui.all")
+	if d.exchange >= 0 {
+		exchangeName = d.b.exchanges[d.exchange].GetName()
+	}
+	filter := d.filter
+	if filter == "" {
+		filter = "—"
+	}
+	return tr(d.lang, "tui.view", sortTitle(d.lang, d.sort), exchangeName, filter)
+}
+
+// healthLines состояние бирж: число ставок и давность обновления, ошибки — отдельными строками.
+// Зелёный — обновлена в последнем цикле, жёлтый — данные устарели, красный — последняя попытка с ошибкой.
+func (d *dashboard) healthLines(width int, now time.Time) []string {
+	perLine := max(width/dashboardCellWidth, 1)
+	var lines, errors, cells []string
+	for _, health := range d.b.healthReport(now) {
+		var text, color string
+		switch health.State {
+		case healthPending:
+			text, color = "○ "+health.Exchange, ansiDim
+		case healthError:
+			text, color = "✗ "+health.Exchange, ansiRed
+			errors = append(errors, ansiRed+truncate(health.Exchange+": "+health.Error, min(width, dashboardErrorWidth)))
+		case healthStale:
+			text, color = "● "+health.Exchange, ansiYellow
+		default:
+			text, color = "● "+health.Exchange, ansiGreen
+		}
+		if health.LastUpdate != nil {
+			text += fmt.Sprintf(" %d %s", health.Rates, formatDuration(now.Sub(*health.LastUpdate).Truncate(time.Second), d.lang))
+		}
+		cells = append(cells, color+padRight(truncate(text, dashboardCellWidth-1), dashboardCellWidth)+ansiReset)
+		if len(cells) == perLine {
+			lines = append(lines, strings.Join(cells, ""))
+			cells = nil
+		}
+	}
+	if len(cells) > 0 {
+		lines = append(lines, strings.Join(cells, ""))
+	}
+	return append(lines, errors...)
+}
+
+// formatRow строка таблицы: ставки раскрашены по знаку
+func (d *dashboard) formatRow(columns []dashboardColumn, row venueRate, now time.Time, width int) string {
+	payment := "—"
+	if t, ok := nextFundingTime(row.Rate); ok {
+		payment = formatFundingClock(t, d.loc, now, d.lang)
+	}
+	volume := formatVolume(row.Rate)
+	if volume == "" {
+		volume = "—"
+	}
+	rateColor := ""
+	switch {
+	case row.Rate.Rate > 0:
+		rateColor = ansiGreen
+	case row.Rate.Rate < 0:
+		rateColor = ansiRed
+	}
+	cells := []string{
+		row.Exchange,
+		row.Rate.Symbol,
+		fmt.Sprintf("%+.4f%%", row.Rate.Rate*100),
+		fmt.Sprintf("%+.4f%%", row.Rate8h*100),
+		payment,
+		volume,
+	}
+	return formatCells(columns, cells, []string{"", "", rateColor, rateColor, "", ""}, width)
+}
+
+// formatCells выравнивает ячейки по ширине столбцов; столбцы, не влезающие в ширину экрана, отбрасываются
+func formatCells(columns []dashboardColumn, cells, colors []string, width int) string {
+	var b strings.Builder
+	used := 0
+	for i, c := range columns {
+		if used+c.Width > width {
+			break
+		}
+		text := truncate(cells[i], c.Width-1)
+		if c.Right {
+			text = strings.Repeat(" ", c.Width-1-utf8.RuneCountInString(text)) + text + " "
+		} else {
+			text = padRight(text, c.Width)
+		}
+		if colors != nil && colors[i] != "" {
+			text = colors[i] + text + ansiReset
+		}
+		b.WriteString(text)
+		used += c.Width
+	}
+	return b.String()
+}
+
+// truncate обрезает строку до width символов
+func truncate(text string, width int) string {
+	if utf8.RuneCountInString(text) <= width {
+		return text
+	}
+	if width <= 1 {
+		return string([]rune(text)[:max(width, 0)])
+	}
+	return string([]rune(text)[:width-1]) + "…"
+}
+
+// padRight дополняет строку пробелами до width символов
+func padRight(text string, width int) string {
+	if n := utf8.RuneCountInString(text); n < width {
+		return text + strings.Repeat(" ", width-n)
+	}
+	return text
+}
+
+// readKeys читает нажатия и переводит escape-последовательности стрелок и PgUp/PgDn в имена клавиш
+func readKeys(tty *os.File, keys chan<- string) {
+	defer close(keys)
+	buf := make([]byte, 64)
+	for {
+		n, err := tty.Read(buf)
+		if err != nil {
+			return
+		}
+		for input := buf[:n]; len(input) > 0; {
+			key, size := parseKey(input)
+			input = input[size:]
+			if key != "" {
+				keys <- key
+			}
+		}
+	}
+}
+
+// escapeKeys escape-последовательности клавиш, которые понимает панель
+var escapeKeys = map[string]string{
+	"\x1b[A": "up", "\x1b[B": "down",
+	"\x1b[5~": "pgup", "\x1b[6~": "pgdn",
+	"\x1b[H": "home", "\x1b[F": "end",
+	"\x1b[1~": "home", "\x1b[4~": "end",
+	"\x1bOA": "up", "\x1bOB": "down",
+}
+
+// parseKey разбирает первую клавишу во вводе и возвращает её имя и длину в байтах
+func parseKey(input []byte) (string, int) {
+	switch input[0] {
+	case '\r', '\n':
+		return "enter", 1
+	case 0x7f, 0x08:
+		return "backspace", 1
+	case 0x1b:
+		for seq, key := range escapeKeys {
+			if strings.HasPrefix(string(input), seq) {
+				return key, len(seq)
+			}
+		}
+		if len(input) > 1 && (input[1] == '[' || input[1] == 'O') {
+			// Незнакомая последовательность: пропускаем до завершающей буквы или ~
+			for i := 2; i < len(input); i++ {
+				if c := input[i]; c == '~' || c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' {
+					return "", i + 1
+				}
+			}
+			return "", len(input)
+		}
+		return "esc", 1
+	}
+	r, size := utf8.DecodeRune(input)
+	if r == utf8.RuneError || r < ' ' {
+		return "", max(size, 1)
+	}
+	return string(r), size
+}
+
+// enterRawMode переводит терминал в посимвольный ввод без эха и возвращает функцию восстановления
+func enterRawMode(tty *os.File) (func(), error) {
+	state, err := stty(tty, "-g")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := stty(tty, "cbreak", "-echo"); err != nil {
+		return nil, err
+	}
+	return func() { stty(tty, strings.TrimSpace(state)) }, nil
+}
+
+// terminalSize размер терминала в символах; 80×24, если его не удалось узнать
+func terminalSize(tty *os.File) (width, height int) {
+	out, err := stty(tty, "size")
+	if err == nil {
+		if fields := strings.Fields(out); len(fields) == 2 {
+			rows, errRows := strconv.Atoi(fields[0])
+			cols, errCols := strconv.Atoi(fields[1])
+			if errRows == nil && errCols == nil && rows > 0 && cols > 0 {
+				return cols, rows
+			}
+		}
+	}
+	return 80, 24
+}
+
+func stty(tty *os.File, args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = tty
+	out, err := cmd.Output()
+	return string(out), err
+}
@@ -0,0 +1,110 @@
+package bot
+
+import (
+	"encoding/json"
+	"log"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// updateThread содержит поля тем форума, которых нет в tgbotapi.Message
+type updateThread struct {
+	Message *struct {
+		MessageThreadID int  `json:"message_thread_id"`
+		IsTopicMessage  bool `json:"is_topic_message"`
+	} `json:"message"`
+}
+
+// incomingUpdate обновление Telegram вместе с темой форума, из которой пришло сообщение
+type incomingUpdate struct {
+	tgbotapi.Update
+	ThreadID int
+}
+
+// pollUpdates получает обновления через getUpdates напрямую,
+// чтобы не терять message_thread_id сообщений из тем форума
+func (b *Bot) pollUpdates() <-chan incomingUpdate {
+	ch := make(chan incomingUpdate, 100)
+
+	go func() {
+		offset := 0
+		for {
+			params := tgbotapi.Params{}
+			params.AddNonZero("offset", offset)
+			params.AddNonZero("timeout", 60)
+			params.AddInterface("allowed_updates", []string{"message", "channel_post", "my_chat_member", "callback_query"})
+
+			resp, err := b.bot.MakeRequest("getUpdates", params)
+			if err != nil {
+				log.Printf("Ошибка получения обновлений: %v, повтор через 3 секунды", err)
+				time.Sleep(3 * time.Second)
+				continue
+			}
+
+			var updates []tgbotapi.Update
+			if err := json.Unmarshal(resp.Result, &updates); err != nil {
+				log.Printf("Ошибка декодирования обновлений: %v", err)
+				time.Sleep(3 * time.Second)
+				continue
+			}
+			var threads []updateThread
+			if err := json.Unmarshal(resp.Result, &threads); err != nil || len(threads) != len(updates) {
+				threads = make([]updateThread, len(updates))
+			}
+
+			for i, update := range updates {
+				if update.UpdateID < offset {
+					continue
+				}
+				offset = update.UpdateID + 1
+
+				incoming := incomingUpdate{Update: update}
+				if m := threads[i].Message; m != nil && m.IsTopicMessage {
+					incoming.ThreadID = m.MessageThreadID
+				}
+				ch <- incoming
+			}
+		}
+	}()
+
+	return ch
+}
+
+// threadOf возвращает тему форума, из которой пришло сообщение
+func (b *Bot) threadOf(msg *tgbotapi.Message) int {
+	if thread, ok := b.messageThreads.Load(msg); ok {
+		return thread.(int)
+	}
+	return 0
+}
+
+// handleCallback обрабатывает нажатия inline-кнопок
+func (b *Bot) handleCallback(query *tgbotapi.CallbackQuery) {
+	defer logPanic("обработке нажатия кнопки")
+
+	if query.Message == nil {
+		b.answerCallback(query, "")
+		return
+	}
+	if !chatHasAccess(query.Message.Chat.ID) {
+		b.answerCallback(query, tr(userLang(query.Message.Chat.ID, query.From), "callback.no_access"))
+		return
+	}
+
+	switch {
+	case strings.HasPrefix(query.Data, ratesCallbackPrefix):
+		b.handleRatesCallback(query)
+	default:
+		log.Printf("Неизвестный callback %q из чата %d", query.Data, query.Message.Chat.ID)
+		b.answerCallback(query, "")
+	}
+}
+
+// answerCallback подтверждает нажатие кнопки, чтобы в клиенте пропал индикатор загрузки
+func (b *Bot) answerCallback(query *tgbotapi.CallbackQuery, text string) {
+	if _, err := b.bot.Request(tgbotapi.NewCallback(query.ID, text)); err != nil {
+		log.Printf("Ошибка ответа на callback: %v", err)
+	}
+}
@@ -0,0 +1,340 @@
+package bot
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	exchanges "github.com/petrixs/cr-exchanges"
+)
+
+// Веб-панель: статические файлы из web/ и JSON/SSE API над кэшем ставок.
+// Включается переменной WEB_ADDR у бота или запускается отдельно подкомандой web.
+
+const (
+	// webKeepAlive период комментариев в потоке событий, чтобы прокси не закрывали соединение
+	webKeepAlive = 30 * time.Second
+	// maxSpreads сколько монет отдаёт /api/spreads
+	maxSpreads = 500
+)
+
+//go:embed web
+var webAssets embed.FS
+
+// updateFeed оповещает подписчиков о завершении каждого цикла обновления ставок
+type updateFeed struct {
+	mu          sync.Mutex
+	subscribers map[chan time.Time]struct{}
+}
+
+func (f *updateFeed) subscribe() chan time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.subscribers == nil {
+		f.subscribers = make(map[chan time.Time]struct{})
+	}
+	ch := make(chan time.Time, 1)
+	f.subscribers[ch] = struct{}{}
+	return ch
+}
+
+func (f *updateFeed) unsubscribe(ch chan time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.subscribers, ch)
+}
+
+// publish не блокируется: медленный подписчик получит только последнее событие
+func (f *updateFeed) publish(t time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for ch := range f.subscribers {
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- t
+	}
+}
+
+// spreadLeg сторона арбитражной пары
+type spreadLeg struct {
+	Exchange    string     `json:"exchange"`
+	Symbol      string     `json:"symbol"`
+	Rate        float64    `json:"rate"`
+	Rate8h      float64    `json:"rate_8h"`
+	NextFunding *time.Time `json:"next_funding,omitempty"`
+}
+
+// symbolSpread лучшая пара монеты: лонг на бирже с минимальной ставкой, шорт — с максимальной
+type symbolSpread struct {
+	Base     string    `json:"base"`
+	Long     spreadLeg `json:"long"`
+	Short    spreadLeg `json:"short"`
+	Spread8h float64   `json:"spread_8h"`
+	Venues   int       `json:"venues"`
+}
+
+func newSpreadLeg(r venueRate) spreadLeg {
+	leg := spreadLeg{Exchange: r.Exchange, Symbol: r.Rate.Symbol, Rate: r.Rate.Rate, Rate8h: r.Rate8h}
+	if t, ok := nextFundingTime(r.Rate); ok {
+		leg.NextFunding = &t
+	}
+	return leg
+}
+
+// symbolSpreads лучшие пары всех монет, торгующихся хотя бы на двух биржах, по убыванию спреда
+func symbolSpreads(rates map[string][]exchanges.FundingRate) []symbolSpread {
+	var spreads []symbolSpread
+	for base, found := range groupBySymbol(rates) {
+		long, short, ok := bestPair(found)
+		if !ok {
+			continue
+		}
+		venues := make(map[string]struct{})
+		for _, r := range found {
+			venues[r.Exchange] = struct{}{}
+		}
+		spreads = append(spreads, symbolSpread{
+			Base:     base,
+			Long:     newSpreadLeg(long),
+			Short:    newSpreadLeg(short),
+			Spread8h: short.Rate8h - long.Rate8h,
+			Venues:   len(venues),
+		})
+	}
+	sort.Slice(spreads, func(i, j int) bool {
+		if spreads[i].Spread8h != spreads[j].Spread8h {
+			return spreads[i].Spread8h > spreads[j].Spread8h
+		}
+		return spreads[i].Base < spreads[j].Base
+	})
+	return spreads
+}
+
+// ServeWeb запускает веб-панель без Telegram и RabbitMQ: опрос бирж, запись истории ставок и HTTP-сервер на addr
+func ServeWeb(exs []exchanges.Exchange, addr string) error {
+	b := newHeadlessBot(exs, newRateHistory())
+	go b.startRatesUpdateLoop()
+	return b.serveWeb(addr)
+}
+
+// serveWeb обслуживает веб-панель на addr; возвращается только с ошибкой
+func (b *Bot) serveWeb(addr string) error {
+	handler, err := b.webHandler()
+	if err != nil {
+		return err
+	}
+	server := &http.Server{
+		Addr:    addr,
+		Handler: handler,
+		// WriteTimeout не задаём: поток событий живёт, пока открыта страница
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	log.Printf("Веб-панель доступна на http://%s", addr)
+	return server.ListenAndServe()
+}
+
+// webHandler маршруты веб-панели
+func (b *Bot) webHandler() (http.Handler, error) {
+	static, err := fs.Sub(webAssets, "web")
+	if err != nil {
+		return nil, fmt.Errorf("статические файлы веб-панели: %v", err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("GET /", http.FileServerFS(static))
+	mux.HandleFunc("GET /api/i18n", b.handleAPIMessages)
+	mux.HandleFunc("GET /api/rates", b.handleAPIRates)
+	mux.HandleFunc("GET /api/spreads", b.handleAPISpreads)
+	mux.HandleFunc("GET /api/health", b.handleAPIHealth)
+	mux.HandleFunc("GET /api/chart", b.handleAPIChart)
+	mux.HandleFunc("GET /api/events", b.handleAPIEvents)
+	return mux, nil
+}
+
+// requestLang язык ответа: параметр lang, затем Accept-Language, затем DEFAULT_LANG
+func requestLang(r *http.Request) string {
+	if lang := normalizeLang(r.URL.Query().Get("lang")); lang != "" {
+		return lang
+	}
+	for _, part := range strings.Split(r.Header.Get("Accept-Language"), ",") {
+		tag, _, _ := strings.Cut(part, ";")
+		if lang := normalizeLang(tag); lang != "" {
+			return lang
+		}
+	}
+	return defaultLang()
+}
+
+// writeJSON отправляет v в формате JSON
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Ошибка отправки ответа веб-панели: %v", err)
+	}
+}
+
+// webMessagePrefixes ключи каталога, которые нужны странице
+var webMessagePrefixes = []string{"web.", "col.", "sort.", "unit."}
+
+// handleAPIMessages отдаёт подписи интерфейса из каталога на языке запроса
+func (b *Bot) handleAPIMessages(w http.ResponseWriter, r *http.Request) {
+	lang := requestLang(r)
+	texts := map[string]string{"lang": lang}
+	for key := range messages {
+		for _, prefix := range webMessagePrefixes {
+			if strings.HasPrefix(key, prefix) {
+				texts[key] = lookup(lang, key)
+			}
+		}
+	}
+	writeJSON(w, texts)
+}
+
+// handleAPIRates отдаёт текущие ставки всех бирж в формате выгрузки
+func (b *Bot) handleAPIRates(w http.ResponseWriter, r *http.Request) {
+	rows := currentExportRows(b.cache.GetAllRates(), rateFilter{}, time.Now())
+	if rows == nil {
+		rows = []exportRow{}
+	}
+	writeJSON(w, rows)
+}
+
+// handleAPISpreads отдаёт лучшие арбитражные пары; min — минимальный спред за 8 часов в процентах (0.05 или 0.05%)
+func (b *Bot) handleAPISpreads(w http.ResponseWriter, r *http.Request) {
+	var min float64
+	if value := r.URL.Query().Get("min"); value != "" {
+		parsed, err := parseThresholdValue(value)
+		if err != nil {
+			http.Error(w, localize(requestLang(r), err), http.StatusBadRequest)
+			return
+		}
+		min = parsed
+	}
+	spreads := []symbolSpread{}
+	for _, spread := range symbolSpreads(b.cache.GetAllRates()) {
+		if spread.Spread8h < min || len(spreads) == maxSpreads {
+			break
+		}
+		spreads = append(spreads, spread)
+	}
+	writeJSON(w, spreads)
+}
+
+// handleAPIHealth отдаёт состояние бирж
+func (b *Bot) handleAPIHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, struct {
+		Interval int              `json:"interval_seconds"`
+		History  bool             `json:"history"`
+		Health   []exchangeHealth `json:"exchanges"`
+	}{
+		Interval: int(ratesUpdateInterval / time.Second),
+		History:  b.history != nil,
+		Health:   b.healthReport(time.Now()),
+	})
+}
+
+// handleAPIChart отдаёт PNG-график истории монеты: /api/chart?symbol=BTC&period=7d&exchanges=binance,bybit&tz=Europe/Kyiv
+func (b *Bot) handleAPIChart(w http.ResponseWriter, r *http.Request) {
+	lang := requestLang(r)
+	query := r.URL.Query()
+	base := normalizeSymbol(query.Get("symbol"))
+	if base == "" {
+		http.Error(w, tr(lang, "web.chart.no_symbol"), http.StatusBadRequest)
+		return
+	}
+	if b.history == nil {
+		http.Error(w, tr(lang, "web.chart.no_history"), http.StatusNotFound)
+		return
+	}
+
+	var args []string
+	for _, name := range []string{"period", "exchanges"} {
+		if value := query.Get(name); value != "" {
+			args = append(args, value)
+		}
+	}
+	opts, err := parseChartOptions(args)
+	if err == nil {
+		err = opts.Filter.validateExchanges(b.exchanges)
+	}
+	if err != nil {
+		http.Error(w, localize(lang, err), http.StatusBadRequest)
+		return
+	}
+	if opts.Period > b.history.retention {
+		http.Error(w, tr(lang, "history.too_long", formatDuration(b.history.retention, lang)), http.StatusBadRequest)
+		return
+	}
+
+	loc := defaultLocation()
+	if name := query.Get("tz"); name != "" {
+		if parsed, err := loadTimezone(name); err == nil {
+			loc = parsed
+		}
+	}
+
+	now := time.Now()
+	points, err := b.symbolHistory(base, opts, now)
+	if err != nil {
+		log.Printf("Ошибка чтения истории для веб-графика %s: %v", base, err)
+		http.Error(w, tr(lang, "chart.error"), http.StatusInternalServerError)
+		return
+	}
+	if len(points) == 0 {
+		http.Error(w, tr(lang, "chart.no_data", base, formatDuration(b.history.interval, lang)), http.StatusNotFound)
+		return
+	}
+	data, err := b.symbolChart(base, opts, points, loc, now).render()
+	if err != nil {
+		log.Printf("Ошибка построения веб-графика %s: %v", base, err)
+		http.Error(w, tr(lang, "chart.error"), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write(data)
+}
+
+// handleAPIEvents поток Server-Sent Events: событие update после каждого обновления ставок
+func (b *Bot) handleAPIEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Accel-Buffering", "no")
+
+	updates := b.updates.subscribe()
+	defer b.updates.unsubscribe(updates)
+
+	fmt.Fprintf(w, "retry: %d\n\n", (5 * time.Second).Milliseconds())
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(webKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case t := <-updates:
+			fmt.Fprintf(w, "event: update\ndata: {\"time\":%q}\n\n", t.UTC().Format(time.RFC3339))
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": ping\n\n")
+		case <-r.Context().Done():
+			return
+		}
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,329 @@
+// Веб-панель фандинг-скринера: данные из /api, обновление по событиям /api/events.
+"use strict";
+
+// maxRows сколько строк таблицы рисовать: остальное доступно через фильтр
+const maxRows = 500;
+
+let texts = {};
+const state = {
+  rates: [],
+  spreads: [],
+  health: null,
+  updated: null,
+  rateSort: { key: "abs", dir: -1 },
+  spreadSort: { key: "spread_8h", dir: -1 },
+};
+
+// t подпись из каталога; %s и %d подставляются по порядку, как в fmt
+function t(key, ...args) {
+  let i = 0;
+  return (texts[key] || key).replace(/%%|%[sd]/g, (m) => (m === "%%" ? "%" : String(args[i++])));
+}
+
+function $(id) {
+  return document.getElementById(id);
+}
+
+function el(tag, attrs, ...children) {
+  const node = document.createElement(tag);
+  for (const [name, value] of Object.entries(attrs || {})) {
+    if (value !== undefined && value !== null) node.setAttribute(name, value);
+  }
+  for (const child of children) {
+    node.append(child instanceof Node ? child : String(child));
+  }
+  return node;
+}
+
+function formatRate(value) {
+  return (value > 0 ? "+" : "") + (value * 100).toFixed(4) + "%";
+}
+
+function rateClass(value) {
+  return value > 0 ? "num pos" : value < 0 ? "num neg" : "num";
+}
+
+// formatVolume как formatVolume в боте: объём в USDT, иначе в монетах
+function formatVolume(row) {
+  const scale = (v, units) => {
+    for (const [size, suffix] of units) {
+      if (v >= size) return (v / size).toFixed(1) + suffix;
+    }
+    return v.toFixed(0);
+  };
+  if (row.volume_usdt_24h > 0) return "$" + scale(row.volume_usdt_24h, [[1e6, "M"], [1e3, "K"]]);
+  if (row.volume_24h > 0) return scale(row.volume_24h, [[1e9, "B"], [1e6, "M"], [1e3, "K"]]);
+  return "—";
+}
+
+function usdVolume(row) {
+  return row.volume_usdt_24h || 0;
+}
+
+// formatDuration как formatDuration в боте: 1ч 12м, 45м, 2д 3ч, <1м
+function formatDuration(ms) {
+  const day = texts["unit.day"], hour = texts["unit.hour"], minute = texts["unit.minute"];
+  if (ms < 60000) return "<1" + minute;
+  const total = Math.floor(ms / 60000);
+  const days = Math.floor(total / 1440), hours = Math.floor(total / 60) % 24, minutes = total % 60;
+  if (days > 0) return hours > 0 ? `${days}${day} ${hours}${hour}` : `${days}${day}`;
+  if (hours > 0) return minutes > 0 ? `${hours}${hour} ${minutes}${minute}` : `${hours}${hour}`;
+  return `${minutes}${minute}`;
+}
+
+function formatClock(iso) {
+  return new Date(iso).toLocaleTimeString(texts.lang, { hour: "2-digit", minute: "2-digit" });
+}
+
+// formatFunding время выплаты с обратным отсчётом: 19:00 (1ч 12м)
+function formatFunding(iso) {
+  if (!iso) return "—";
+  const left = new Date(iso) - Date.now();
+  return formatClock(iso) + (left > 0 ? ` (${formatDuration(left)})` : "");
+}
+
+function matchFilter(value, haystack) {
+  const terms = value.toLowerCase().split(/\s+/).filter(Boolean);
+  const text = haystack.join(" ").toLowerCase();
+  return terms.every((term) => text.includes(term));
+}
+
+function compare(a, b) {
+  if (typeof a === "string" || typeof b === "string") return String(a).localeCompare(String(b));
+  return (a ?? -Infinity) - (b ?? -Infinity);
+}
+
+// sortRows сортирует копию строк; value достаёт значение столбца
+function sortRows(rows, sort, value) {
+  return rows
+    .map((row) => [value(row, sort.key), row])
+    .sort((a, b) => sort.dir * compare(a[0], b[0]))
+    .map(([, row]) => row);
+}
+
+// renderTable рисует таблицу; щелчок по заголовку меняет сортировку
+function renderTable(table, columns, rows, sort, onSort) {
+  const head = el("tr");
+  for (const column of columns) {
+    const classes = [column.num ? "num" : "", sort.key === column.sort ? "sorted" : "", sort.dir > 0 ? "asc" : ""];
+    const th = el("th", { class: classes.join(" ").trim(), title: column.hint }, column.title);
+    if (column.sort) {
+      th.onclick = () => {
+        if (sort.key === column.sort) {
+          sort.dir = -sort.dir;
+        } else {
+          sort.key = column.sort;
+          sort.dir = column.num ? -1 : 1;
+        }
+        onSort();
+      };
+    }
+    head.append(th);
+  }
+  const body = el("tbody");
+  if (rows.length === 0) {
+    body.append(el("tr", {}, el("td", { colspan: columns.length, class: "muted" }, t("web.empty"))));
+  }
+  for (const row of rows) {
+    const tr = el("tr");
+    for (const column of columns) {
+      const content = column.render(row);
+      tr.append(el("td", { class: column.cellClass ? column.cellClass(row) : column.num ? "num" : null }, content));
+    }
+    body.append(tr);
+  }
+  table.replaceChildren(el("thead", {}, head), body);
+}
+
+function symbolLink(symbol, base) {
+  const link = el("a", { class: "symbol" }, symbol);
+  link.onclick = () => showHistory(base);
+  return link;
+}
+
+const rateColumns = () => [
+  { title: texts["col.exchange"], sort: "exchange", render: (r) => r.exchange },
+  { title: texts["col.symbol"], sort: "symbol", render: (r) => symbolLink(r.symbol, r.base) },
+  { title: texts["col.rate"], sort: "rate", num: true, render: (r) => formatRate(r.rate), cellClass: (r) => rateClass(r.rate) },
+  { title: texts["col.rate8h"], sort: "rate_8h", num: true, render: (r) => formatRate(r.rate_8h), cellClass: (r) => rateClass(r.rate_8h) },
+  { title: texts["col.payment"], sort: "next_funding", render: (r) => formatFunding(r.next_funding) },
+  { title: texts["col.volume"], sort: "volume", num: true, render: formatVolume },
+];
+
+function rateValue(row, key) {
+  switch (key) {
+    case "abs": return Math.abs(row.rate_8h);
+    case "volume": return usdVolume(row);
+    case "next_funding": return row.next_funding ? Date.parse(row.next_funding) : Infinity;
+    default: return row[key];
+  }
+}
+
+function renderRates() {
+  const filter = $("rates-filter").value;
+  const exchange = $("rates-exchange").value;
+  const rows = state.rates.filter(
+    (r) => (!exchange || r.exchange === exchange) && matchFilter(filter, [r.exchange, r.symbol, r.base]),
+  );
+  const sorted = sortRows(rows, state.rateSort, rateValue);
+  renderTable($("rates-table"), rateColumns(), sorted.slice(0, maxRows), state.rateSort, renderRates);
+  $("rates-shown").textContent = t("web.shown", Math.min(rows.length, maxRows), rows.length);
+}
+
+const spreadColumns = () => [
+  { title: texts["web.col.base"], sort: "base", render: (s) => symbolLink(s.base, s.base) },
+  { title: texts["web.col.long"], sort: "long", render: (s) => `${s.long.exchange} ${s.long.symbol}` },
+  { title: texts["col.rate8h"], sort: "long_rate", num: true, render: (s) => formatRate(s.long.rate_8h), cellClass: (s) => rateClass(s.long.rate_8h) },
+  { title: texts["web.col.short"], sort: "short", render: (s) => `${s.short.exchange} ${s.short.symbol}` },
+  { title: texts["col.rate8h"], sort: "short_rate", num: true, render: (s) => formatRate(s.short.rate_8h), cellClass: (s) => rateClass(s.short.rate_8h) },
+  { title: texts["web.col.spread"], sort: "spread_8h", num: true, render: (s) => formatRate(s.spread_8h), cellClass: () => "num pos" },
+  { title: texts["web.col.venues"], sort: "venues", num: true, render: (s) => s.venues },
+];
+
+function spreadValue(spread, key) {
+  switch (key) {
+    case "long": return spread.long.exchange;
+    case "short": return spread.short.exchange;
+    case "long_rate": return spread.long.rate_8h;
+    case "short_rate": return spread.short.rate_8h;
+    default: return spread[key];
+  }
+}
+
+function renderSpreads() {
+  const filter = $("spreads-filter").value;
+  const min = parseFloat($("spreads-min").value) / 100 || 0;
+  const rows = state.spreads.filter(
+    (s) => s.spread_8h >= min && matchFilter(filter, [s.base, s.long.exchange, s.short.exchange]),
+  );
+  const sorted = sortRows(rows, state.spreadSort, spreadValue);
+  renderTable($("spreads-table"), spreadColumns(), sorted.slice(0, maxRows), state.spreadSort, renderSpreads);
+  $("spreads-shown").textContent = t("web.shown", Math.min(rows.length, maxRows), rows.length);
+}
+
+function renderHealth() {
+  if (!state.health) return;
+  const columns = [
+    { title: texts["col.exchange"], render: (h) => h.exchange },
+    { title: texts["web.col.state"], render: (h) => t("web.health." + h.state), cellClass: (h) => "state-" + h.state },
+    { title: texts["col.rate"], num: true, render: (h) => (h.last_update ? h.rates : "—") },
+    { title: texts["web.col.updated"], render: (h) => (h.last_update ? t("web.health.ago", formatDuration(Date.now() - new Date(h.last_update))) : "—") },
+    { title: texts["web.col.error"], render: (h) => h.error || "", cellClass: () => "error" },
+  ];
+  renderTable($("health-table"), columns, state.health.exchanges, {}, () => {});
+}
+
+function renderExchanges() {
+  const select = $("rates-exchange");
+  const current = select.value;
+  const names = state.health ? state.health.exchanges.map((h) => h.exchange) : [];
+  select.replaceChildren(el("option", { value: "" }, t("web.all_exchanges")), ...names.map((name) => el("option", { value: name }, name)));
+  select.value = names.includes(current) ? current : "";
+}
+
+function renderStatus(online) {
+  const status = $("status");
+  status.classList.toggle("offline", !online);
+  const parts = [online ? t("web.live") : t("web.offline")];
+  if (state.updated) parts.push(t("web.updated", formatClock(state.updated)));
+  status.textContent = parts.join(" · ");
+}
+
+async function getJSON(path) {
+  const response = await fetch(path, { cache: "no-store" });
+  if (!response.ok) throw new Error(await response.text());
+  return response.json();
+}
+
+async function refresh() {
+  const [rates, spreads, health] = await Promise.all([getJSON("api/rates"), getJSON("api/spreads"), getJSON("api/health")]);
+  state.rates = rates;
+  state.spreads = spreads;
+  state.health = health;
+  state.updated = health.exchanges.map((h) => h.last_update).filter(Boolean).sort().pop() || null;
+  renderExchanges();
+  renderRates();
+  renderSpreads();
+  renderHealth();
+}
+
+async function showHistory(base) {
+  if (base) {
+    $("history-symbol").value = base;
+    switchTab("history");
+  }
+  const params = new URLSearchParams({
+    symbol: $("history-symbol").value,
+    period: $("history-period").value,
+    tz: Intl.DateTimeFormat().resolvedOptions().timeZone || "",
+    lang: texts.lang,
+  });
+  if ($("history-exchanges").value.trim()) params.set("exchanges", $("history-exchanges").value.replace(/\s+/g, ""));
+
+  const message = $("history-message");
+  const chart = $("history-chart");
+  const response = await fetch("api/chart?" + params, { cache: "no-store" });
+  if (!response.ok) {
+    chart.hidden = true;
+    message.hidden = false;
+    message.textContent = await response.text();
+    return;
+  }
+  if (chart.src) URL.revokeObjectURL(chart.src);
+  chart.src = URL.createObjectURL(await response.blob());
+  chart.alt = params.get("symbol");
+  chart.hidden = false;
+  message.hidden = true;
+}
+
+function switchTab(name) {
+  for (const button of document.querySelectorAll("nav button")) {
+    button.classList.toggle("active", button.dataset.tab === name);
+  }
+  for (const tab of document.querySelectorAll(".tab")) {
+    tab.classList.toggle("active", tab.id === name);
+  }
+  location.hash = name;
+}
+
+// listen подписывается на события обновления; EventSource сам переподключается после обрыва
+function listen() {
+  const events = new EventSource("api/events");
+  events.onopen = () => renderStatus(true);
+  events.onerror = () => renderStatus(false);
+  events.addEventListener("update", () => refresh().then(() => renderStatus(true)).catch(() => renderStatus(false)));
+}
+
+async function main() {
+  texts = await getJSON("api/i18n");
+  document.documentElement.lang = texts.lang;
+  document.title = t("web.title");
+  for (const node of document.querySelectorAll("[data-text]")) node.textContent = t(node.dataset.text);
+  for (const node of document.querySelectorAll("[data-placeholder]")) node.placeholder = t(node.dataset.placeholder);
+
+  for (const button of document.querySelectorAll("nav button")) {
+    button.onclick = () => switchTab(button.dataset.tab);
+  }
+  $("rates-filter").oninput = renderRates;
+  $("rates-exchange").onchange = renderRates;
+  $("spreads-filter").oninput = renderSpreads;
+  $("spreads-min").oninput = renderSpreads;
+  $("history-form").onsubmit = (event) => {
+    event.preventDefault();
+    showHistory();
+  };
+  if (location.hash && document.querySelector(`nav button[data-tab="${location.hash.slice(1)}"]`)) {
+    switchTab(location.hash.slice(1));
+  }
+
+  await refresh().catch(() => renderStatus(false));
+  listen();
+
+  // Обратный отсчёт до выплат и давность обновления бирж идут по секундам
+  setInterval(() => {
+    if ($("rates").classList.contains("active")) renderRates();
+    if ($("health").classList.contains("active")) renderHealth();
+  }, 1000);
+}
+
+main();
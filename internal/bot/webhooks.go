@@ -0,0 +1,342 @@
+package bot
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// webhookTimeout таймаут одного запроса к вебхуку
+	webhookTimeout = 15 * time.Second
+	// webhookAttempts попыток на запрос при 429 и ошибках сервера
+	webhookAttempts = 3
+	// webhookRetryDelay пауза перед повтором, если сервис не прислал Retry-After
+	webhookRetryDelay = 2 * time.Second
+	// webhookMaxRetryDelay дольше рассылка не ждёт, даже если сервис просит
+	webhookMaxRetryDelay = 30 * time.Second
+	// chatRowLimit сколько ставок биржи показывать в чатах Discord и Slack
+	chatRowLimit = 20
+
+	// Ограничения Discord: поле embed, сумма всех embed в сообщении, полей в embed, текст сообщения
+	discordFieldLimit   = 1024
+	discordEmbedLimit   = 5500
+	discordFieldsLimit  = 25
+	discordContentLimit = 2000
+	// Ограничения Slack: текст блока section, блоков в сообщении
+	slackSectionLimit = 3000
+	slackBlocksLimit  = 50
+)
+
+// webhookNotifier доставка POST-запросами с JSON; тела запросов собирают рендеры канала.
+// Пустая рассылка (нет ставок выше порога) во внешние каналы не отправляется.
+type webhookNotifier struct {
+	channel string
+	client  *http.Client
+	// rowLimit сколько ставок биржи показывать; 0 — все
+	rowLimit    int
+	renderRates func(r ratesReport) []any
+	renderText  func(text string) []any
+}
+
+// newWebhookNotifiers каналы, доставляющие рассылки через вебхуки
+func newWebhookNotifiers() map[string]Notifier {
+	client := &http.Client{Timeout: webhookTimeout}
+	return map[string]Notifier{
+		channelDiscord: &webhookNotifier{channel: channelDiscord, client: client, rowLimit: chatRowLimit, renderRates: renderDiscord, renderText: discordAnnouncement},
+		channelSlack:   &webhookNotifier{channel: channelSlack, client: client, rowLimit: chatRowLimit, renderRates: renderSlack, renderText: slackAnnouncement},
+		channelWebhook: &webhookNotifier{channel: channelWebhook, client: client, renderRates: renderWebhook, renderText: webhookAnnouncement},
+	}
+}
+
+func (n *webhookNotifier) Channel() string { return n.channel }
+
+func (n *webhookNotifier) NotifyRates(to recipient, alert *ratesAlert) error {
+	report := alert.report(n.rowLimit)
+	if report.Empty() {
+		return nil
+	}
+	return n.postAll(to, n.renderRates(report))
+}
+
+func (n *webhookNotifier) NotifyText(to recipient, text string) error {
+	return n.postAll(to, n.renderText(text))
+}
+
+// postAll отправляет части сообщения по порядку и останавливается на первой ошибке
+func (n *webhookNotifier) postAll(to recipient, payloads []any) error {
+	for _, payload := range payloads {
+		if err := n.post(to, payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// post отправляет одно тело запроса; при 429 и ошибках сервера повторяет после паузы
+func (n *webhookNotifier) post(to recipient, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	for attempt := 1; ; attempt++ {
+		req, err := http.NewRequest(http.MethodPost, to.URL, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		for name, value := range to.Headers {
+			req.Header.Set(name, value)
+		}
+
+		resp, err := n.client.Do(req)
+		if err != nil {
+			if attempt < webhookAttempts {
+				time.Sleep(webhookRetryDelay)
+				continue
+			}
+			return err
+		}
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+		resp.Body.Close()
+
+		switch {
+		case resp.StatusCode < 300:
+			return nil
+		case (resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500) && attempt < webhookAttempts:
+			time.Sleep(retryAfter(resp.Header))
+			continue
+		}
+		return fmt.Errorf("%s ответил %s", n.channel, resp.Status)
+	}
+}
+
+// retryAfter пауза из заголовка Retry-After (секунды, у Discord бывают дробные)
+func retryAfter(header http.Header) time.Duration {
+	seconds, err := strconv.ParseFloat(header.Get("Retry-After"), 64)
+	if err != nil || seconds <= 0 {
+		return webhookRetryDelay
+	}
+	delay := time.Duration(seconds * float64(time.Second))
+	if delay > webhookMaxRetryDelay {
+		return webhookMaxRetryDelay
+	}
+	return delay
+}
+
+// codeBlock собирает ставки биржи в блок кода ``` не длиннее limit символов;
+// не поместившиеся строки добавляются к приписке «и ещё N»
+func codeBlock(r ratesReport, section reportSection, limit int) string {
+	lines := sectionLines(r, section)
+	for shown := len(lines); shown > 0; shown-- {
+		text := "```\n" + strings.Join(lines[:shown], "\n") + "\n```"
+		if hidden := section.Hidden + len(lines) - shown; hidden > 0 {
+			text += "\n" + trn(r.Lang, "notify.more", hidden)
+		}
+		if utf8.RuneCountInString(text) <= limit {
+			return text
+		}
+	}
+	return trn(r.Lang, "notify.more", section.Hidden+len(lines))
+}
+
+// htmlTag открывающий или закрывающий тег разметки Telegram
+var htmlTag = regexp.MustCompile(`</?([a-z-]+)[^>]*>`)
+
+// convertHTML заменяет теги Telegram на разметку канала; теги без замены удаляются
+func convertHTML(text string, tags map[string]string) string {
+	return htmlTag.ReplaceAllStringFunc(text, func(tag string) string {
+		return tags[htmlTag.FindStringSubmatch(tag)[1]]
+	})
+}
+
+// splitText делит текст по строкам на части не длиннее limit символов
+func splitText(text string, limit int) []string {
+	var parts []string
+	var current strings.Builder
+	for _, line := range strings.Split(text, "\n") {
+		for utf8.RuneCountInString(line) > limit {
+			runes := []rune(line)
+			parts = append(parts, string(runes[:limit]))
+			line = string(runes[limit:])
+		}
+		if current.Len() > 0 && utf8.RuneCountInString(current.String())+1+utf8.RuneCountInString(line) > limit {
+			parts = append(parts, current.String())
+			current.Reset()
+		}
+		if current.Len() > 0 {
+			current.WriteString("\n")
+		}
+		current.WriteString(line)
+	}
+	if current.Len() > 0 {
+		parts = append(parts, current.String())
+	}
+	return parts
+}
+
+// Discord
+
+type discordMessage struct {
+	Content string         `json:"content,omitempty"`
+	Embeds  []discordEmbed `json:"embeds,omitempty"`
+}
+
+type discordEmbed struct {
+	Title     string         `json:"title,omitempty"`
+	Color     int            `json:"color,omitempty"`
+	Fields    []discordField `json:"fields,omitempty"`
+	Footer    *discordFooter `json:"footer,omitempty"`
+	Timestamp string         `json:"timestamp,omitempty"`
+}
+
+type discordField struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type discordFooter struct {
+	Text string `json:"text"`
+}
+
+// discordColor цвет полосы embed: зелёный для обычной рассылки, синий для дайджеста
+func discordColor(r ratesReport) int {
+	if r.Digest {
+		return 0x1f77b4
+	}
+	return 0x1a8f3c
+}
+
+// renderDiscord сообщения с embed, поле на каждую биржу; биржи разбиваются на сообщения
+// так, чтобы каждое укладывалось в лимиты Discord
+func renderDiscord(r ratesReport) []any {
+	title, footer := reportTitle(r), reportFooter(r)
+	newEmbed := func() discordEmbed {
+		return discordEmbed{
+			Title:     title,
+			Color:     discordColor(r),
+			Footer:    &discordFooter{Text: footer},
+			Timestamp: r.Time.UTC().Format(time.RFC3339),
+		}
+	}
+
+	var payloads []any
+	embed := newEmbed()
+	size := utf8.RuneCountInString(title + footer)
+	for _, section := range r.Sections {
+		field := discordField{Name: "📈 " + section.Exchange, Value: codeBlock(r, section, discordFieldLimit)}
+		fieldSize := utf8.RuneCountInString(field.Name + field.Value)
+		if len(embed.Fields) > 0 && (size+fieldSize > discordEmbedLimit || len(embed.Fields) == discordFieldsLimit) {
+			payloads = append(payloads, discordMessage{Embeds: []discordEmbed{embed}})
+			embed = newEmbed()
+			size = utf8.RuneCountInString(title + footer)
+		}
+		embed.Fields = append(embed.Fields, field)
+		size += fieldSize
+	}
+	if len(embed.Fields) > 0 {
+		payloads = append(payloads, discordMessage{Embeds: []discordEmbed{embed}})
+	}
+	return payloads
+}
+
+// discordTags разметка Telegram в Markdown Discord
+var discordTags = map[string]string{"b": "**", "strong": "**", "i": "*", "em": "*", "u": "__", "s": "~~", "code": "`", "pre": "```"}
+
+func discordAnnouncement(text string) []any {
+	var payloads []any
+	for _, part := range splitText(html.UnescapeString(convertHTML(text, discordTags)), discordContentLimit) {
+		payloads = append(payloads, discordMessage{Content: part})
+	}
+	return payloads
+}
+
+// Slack
+
+type slackMessage struct {
+	Text   string       `json:"text"`
+	Blocks []slackBlock `json:"blocks,omitempty"`
+}
+
+type slackBlock struct {
+	Type     string      `json:"type"`
+	Text     *slackText  `json:"text,omitempty"`
+	Elements []slackText `json:"elements,omitempty"`
+}
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// slackEscape экранирует управляющие символы mrkdwn
+var slackEscape = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// renderSlack блоки header, section на каждую биржу и context с порогами;
+// биржи разбиваются на сообщения не длиннее лимита блоков Slack
+func renderSlack(r ratesReport) []any {
+	title := reportTitle(r)
+	newMessage := func() slackMessage {
+		return slackMessage{Text: title, Blocks: []slackBlock{{Type: "header", Text: &slackText{Type: "plain_text", Text: title}}}}
+	}
+	footer := slackBlock{Type: "context", Elements: []slackText{{Type: "mrkdwn", Text: slackEscape.Replace(reportFooter(r))}}}
+
+	var payloads []any
+	msg := newMessage()
+	for _, section := range r.Sections {
+		if len(msg.Blocks) == slackBlocksLimit-1 {
+			payloads = append(payloads, msg)
+			msg = newMessage()
+		}
+		header := "*📈 " + slackEscape.Replace(section.Exchange) + "*\n"
+		block := codeBlock(r, section, slackSectionLimit-utf8.RuneCountInString(header))
+		msg.Blocks = append(msg.Blocks, slackBlock{Type: "section", Text: &slackText{Type: "mrkdwn", Text: header + slackEscape.Replace(block)}})
+	}
+	if len(msg.Blocks) > 1 {
+		payloads = append(payloads, msg)
+	}
+	if len(payloads) > 0 {
+		last := payloads[len(payloads)-1].(slackMessage)
+		last.Blocks = append(last.Blocks, footer)
+		payloads[len(payloads)-1] = last
+	}
+	return payloads
+}
+
+// slackTags разметка Telegram в mrkdwn Slack; сущности &lt; &gt; &amp; Slack понимает сам
+var slackTags = map[string]string{"b": "*", "strong": "*", "i": "_", "em": "_", "s": "~", "code": "`", "pre": "```"}
+
+func slackAnnouncement(text string) []any {
+	text = strings.ReplaceAll(convertHTML(text, slackTags), "&quot;", `"`)
+	return []any{slackMessage{Text: text}}
+}
+
+// Универсальный вебхук: ставки — renderJSON без ограничения числа строк, объявления — текст и исходный HTML
+
+type webhookMessage struct {
+	Type string    `json:"type"`
+	Time time.Time `json:"time"`
+	Text string    `json:"text"`
+	HTML string    `json:"html"`
+}
+
+func renderWebhook(r ratesReport) []any {
+	return []any{renderJSON(r)}
+}
+
+func webhookAnnouncement(text string) []any {
+	return []any{webhookMessage{
+		Type: "text",
+		Time: time.Now().Truncate(time.Second),
+		Text: plainText(text),
+		HTML: text,
+	}}
+}
@@ -2,9 +2,13 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"strconv"
 	"syscall"
 
@@ -18,6 +22,50 @@ import (
 
 func main() {
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
+
+	command, args := "serve", []string(nil)
+	if len(os.Args) > 1 {
+		command, args = os.Args[1], os.Args[2:]
+	}
+
+	switch command {
+	case "serve":
+		serve()
+	case "scan":
+		os.Exit(runScan(args))
+	case "tui":
+		os.Exit(runTUI(args))
+	case "web":
+		os.Exit(runWeb(args))
+	case "export":
+		os.Exit(runExport(args))
+	case "publish-once":
+		os.Exit(runPublishOnce(args))
+	case "check-config":
+		os.Exit(runCheckConfig(args))
+	case "help", "-h", "--help":
+		fmt.Fprint(os.Stdout, usage)
+	default:
+		fmt.Fprintf(os.Stderr, "Неизвестная команда %q\n\n%s", command, usage)
+		os.Exit(2)
+	}
+}
+
+// usage справка по подкомандам
+const usage = `Использование: funding-screener [команда] [аргументы]
+
+Команды:
+  serve          запустить Telegram-бота (по умолчанию)
+  scan           вывести текущие ставки: scan --exchanges binance,bybit --min 0.05% --format table|json|csv
+  tui            живая таблица ставок в терминале
+  web            веб-панель без Telegram: web [--addr 127.0.0.1:8080]
+  export         выгрузить ставки: export csv|json [биржи] [период]
+  publish-once   один раз опубликовать текущие ставки в RabbitMQ и выйти
+  check-config   проверить .env и файл настроек: check-config [--online]
+`
+
+// serve запускает бота: опрос бирж, Telegram и публикация ставок в RabbitMQ
+func serve() {
 	log.Println("Запуск бота...")
 
 	// Обработка сигналов для корректного завершения
@@ -36,25 +84,13 @@ func main() {
 		log.Fatal("TELEGRAM_BOT_TOKEN не установлен")
 	}
 
-	amqpURL := os.Getenv("AMQP_URL")
-	if amqpURL == "" {
-		log.Fatal("AMQP_URL не установлен")
-	}
-
-	queueName := os.Getenv("FUNDING_QUEUE")
-	if queueName == "" {
-		queueName = "funding_rates"
-	}
-
-	fundingTTL := 0
-	if ttlStr := os.Getenv("FUNDING_TTL_MS"); ttlStr != "" {
-		if v, err := strconv.Atoi(ttlStr); err == nil {
-			fundingTTL = v
-		}
+	queue, err := loadQueueConfig()
+	if err != nil {
+		log.Fatal(err)
 	}
 
 	// Создаем клиент RabbitMQ с автоматическим переподключением
-	rabbitClient, err := rabbit.NewRabbitMQClient(amqpURL)
+	rabbitClient, err := rabbit.NewRabbitMQClient(queue.URL)
 	if err != nil {
 		log.Fatalf("Не удалось создать клиент RabbitMQ: %v", err)
 	}
@@ -65,47 +101,12 @@ func main() {
 	// Горутина для отправки ставок в RabbitMQ
 	go func() {
 		for rate := range fundingChan {
-			// Логируем в файл конкретной биржи
-			exchangeLogger := logger.GetExchangeLogger(rate.Exchange)
-			exchangeLogger.Printf("Публикую в RabbitMQ: %+v", rate)
-
-			log.Printf("Публикую в RabbitMQ: %+v", rate)
-			err := rabbitClient.PublishProtoJSONWithTTL(
-				context.Background(), queueName, rate, fundingTTL,
-			)
-			if err != nil {
-				log.Printf("Ошибка отправки в RabbitMQ: %v", err)
-				if exchangeLogger != nil {
-					exchangeLogger.Printf("Ошибка отправки в RabbitMQ: %v", err)
-				}
-			}
+			publish(rabbitClient, queue, rate)
 		}
 	}()
 
-	log.Println("Инициализация бирж...")
-	binance := exchanges.NewBinance()
-	bybit := exchanges.NewBybit()
-	htx := exchanges.NewHTX()
-	okx := exchanges.NewOKX()
-	gate := exchanges.NewGate()
-	kucoin := exchanges.NewKuCoin()
-	bingx := exchanges.NewBingX()
-	mexc := exchanges.NewMEXC()
-	hyperliquid := exchanges.NewHyperliquid()
-	log.Println("Биржи инициализированы")
-
 	log.Println("Создание бота...")
-	telegramBot := bot.NewBot(botToken, []exchanges.Exchange{
-		binance,
-		bybit,
-		htx,
-		okx,
-		gate,
-		kucoin,
-		bingx,
-		mexc,
-		hyperliquid,
-	}, fundingChan)
+	telegramBot := bot.NewBot(botToken, newExchanges(), fundingChan)
 	log.Println("Бот создан")
 
 	log.Println("Запуск бота...")
@@ -119,3 +120,251 @@ func main() {
 	<-sigChan
 	log.Println("Получен сигнал завершения, закрываем приложение...")
 }
+
+// queueConfig настройки публикации ставок в RabbitMQ
+type queueConfig struct {
+	URL   string
+	Queue string
+	TTL   int
+}
+
+// loadQueueConfig читает AMQP_URL, FUNDING_QUEUE и FUNDING_TTL_MS
+func loadQueueConfig() (queueConfig, error) {
+	cfg := queueConfig{
+		URL:   os.Getenv("AMQP_URL"),
+		Queue: os.Getenv("FUNDING_QUEUE"),
+	}
+	if cfg.URL == "" {
+		return cfg, errors.New("AMQP_URL не установлен")
+	}
+	if cfg.Queue == "" {
+		cfg.Queue = "funding_rates"
+	}
+	if ttlStr := os.Getenv("FUNDING_TTL_MS"); ttlStr != "" {
+		if v, err := strconv.Atoi(ttlStr); err == nil {
+			cfg.TTL = v
+		}
+	}
+	return cfg, nil
+}
+
+// publish отправляет ставку в очередь и пишет результат в лог биржи
+func publish(rabbitClient *rabbit.RabbitMQClient, queue queueConfig, rate *proto.FundingRate) error {
+	// Логируем в файл конкретной биржи
+	exchangeLogger := logger.GetExchangeLogger(rate.Exchange)
+	exchangeLogger.Printf("Публикую в RabbitMQ: %+v", rate)
+
+	log.Printf("Публикую в RabbitMQ: %+v", rate)
+	err := rabbitClient.PublishProtoJSONWithTTL(
+		context.Background(), queue.Queue, rate, queue.TTL,
+	)
+	if err != nil {
+		log.Printf("Ошибка отправки в RabbitMQ: %v", err)
+		if exchangeLogger != nil {
+			exchangeLogger.Printf("Ошибка отправки в RabbitMQ: %v", err)
+		}
+	}
+	return err
+}
+
+// newExchanges создаёт клиенты всех поддерживаемых бирж
+func newExchanges() []exchanges.Exchange {
+	log.Println("Инициализация бирж...")
+	exs := []exchanges.Exchange{
+		exchanges.NewBinance(),
+		exchanges.NewBybit(),
+		exchanges.NewHTX(),
+		exchanges.NewOKX(),
+		exchanges.NewGate(),
+		exchanges.NewKuCoin(),
+		exchanges.NewBingX(),
+		exchanges.NewMEXC(),
+		exchanges.NewHyperliquid(),
+	}
+	log.Println("Биржи инициализированы")
+	return exs
+}
+
+// loadEnv загружает .env для разовых команд: без него они тоже работают,
+// .env нужен только для ключей OKX/BingX и настроек истории
+func loadEnv() {
+	if err := godotenv.Load(); err != nil {
+		log.Printf(".env не загружен: %v", err)
+	}
+}
+
+// exitCode код выхода разовой команды; --help не считается ошибкой
+func exitCode(err error) int {
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+		return 0
+	default:
+		return 1
+	}
+}
+
+// runScan выполняет подкоманду scan: текущие ставки в stdout без Telegram и RabbitMQ
+func runScan(args []string) int {
+	defer logger.CloseAll()
+	loadEnv()
+
+	err := bot.Scan(os.Stdout, newExchanges(), args)
+	if err != nil && !errors.Is(err, flag.ErrHelp) {
+		log.Printf("Ошибка scan: %v", err)
+	}
+	return exitCode(err)
+}
+
+// runTUI выполняет подкоманду tui: живая таблица ставок в терминале.
+// Логи пишутся в logs/tui.log, чтобы не портить экран.
+func runTUI(args []string) int {
+	defer logger.CloseAll()
+	loadEnv()
+
+	if len(args) > 0 {
+		fmt.Fprintf(os.Stderr, "tui не принимает аргументов\n\n%s", usage)
+		return 2
+	}
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		log.Printf("Не удалось открыть терминал: %v", err)
+		return 1
+	}
+	defer tty.Close()
+
+	if err := os.MkdirAll("logs", 0755); err != nil {
+		log.Printf("Не удалось создать директорию logs: %v", err)
+		return 1
+	}
+	logFile, err := os.OpenFile(filepath.Join("logs", "tui.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		log.Printf("Не удалось открыть лог панели: %v", err)
+		return 1
+	}
+	defer logFile.Close()
+	log.SetOutput(logFile)
+	defer log.SetOutput(os.Stderr)
+
+	if err := bot.Dashboard(newExchanges(), tty); err != nil {
+		log.SetOutput(os.Stderr)
+		log.Printf("Ошибка панели: %v", err)
+		return 1
+	}
+	return 0
+}
+
+// runWeb выполняет подкоманду web: веб-панель с опросом бирж и историей ставок, без Telegram и RabbitMQ
+func runWeb(args []string) int {
+	defer logger.CloseAll()
+	loadEnv()
+
+	defaultAddr := os.Getenv("WEB_ADDR")
+	if defaultAddr == "" {
+		defaultAddr = "127.0.0.1:8080"
+	}
+	flags := flag.NewFlagSet("web", flag.ContinueOnError)
+	addr := flags.String("addr", defaultAddr, "адрес веб-панели")
+	if err := flags.Parse(args); err != nil {
+		return exitCode(err)
+	}
+
+	if err := bot.ServeWeb(newExchanges(), *addr); err != nil {
+		log.Printf("Ошибка веб-панели: %v", err)
+		return 1
+	}
+	return 0
+}
+
+// runExport выполняет подкоманду export: выгрузка ставок в stdout для cron.
+// Логи идут в stderr, поэтому не смешиваются с данными.
+func runExport(args []string) int {
+	defer logger.CloseAll()
+	loadEnv()
+
+	if err := bot.Export(os.Stdout, newExchanges(), args); err != nil {
+		log.Printf("Ошибка выгрузки: %v", err)
+		fmt.Fprintln(os.Stderr, "Использование: funding-screener export csv|json [биржи] [период]")
+		return 1
+	}
+	return 0
+}
+
+// runPublishOnce выполняет подкоманду publish-once: один опрос бирж и публикация в RabbitMQ, без Telegram
+func runPublishOnce(args []string) int {
+	defer logger.CloseAll()
+	loadEnv()
+
+	if len(args) > 0 {
+		fmt.Fprintf(os.Stderr, "publish-once не принимает аргументов\n\n%s", usage)
+		return 2
+	}
+	queue, err := loadQueueConfig()
+	if err != nil {
+		log.Print(err)
+		return 1
+	}
+	rabbitClient, err := rabbit.NewRabbitMQClient(queue.URL)
+	if err != nil {
+		log.Printf("Не удалось создать клиент RabbitMQ: %v", err)
+		return 1
+	}
+	defer rabbitClient.Close()
+
+	messages := bot.FundingMessages(newExchanges())
+	failed := 0
+	for _, message := range messages {
+		if err := publish(rabbitClient, queue, message); err != nil {
+			failed++
+		}
+	}
+	log.Printf("Опубликовано %d из %d ставок в очередь %s", len(messages)-failed, len(messages), queue.Queue)
+	if failed > 0 || len(messages) == 0 {
+		return 1
+	}
+	return 0
+}
+
+// runCheckConfig выполняет подкоманду check-config: проверка .env и файла настроек.
+// Возвращает 1, если найдены ошибки.
+func runCheckConfig(args []string) int {
+	flags := flag.NewFlagSet("check-config", flag.ContinueOnError)
+	online := flags.Bool("online", false, "проверить токен Telegram и подключение к RabbitMQ")
+	if err := flags.Parse(args); err != nil {
+		return exitCode(err)
+	}
+
+	report := bot.NewConfigReport(os.Stdout)
+	if err := godotenv.Load(); err != nil {
+		report.Warn(".env", "не загружен: %v", err)
+	} else {
+		report.OK(".env", "загружен")
+	}
+	bot.CheckConfig(report, *online)
+
+	queue, err := loadQueueConfig()
+	switch {
+	case err != nil:
+		report.Error("AMQP_URL", "не задан, нужен для serve и publish-once")
+	case *online:
+		if rabbitClient, err := rabbit.NewRabbitMQClient(queue.URL); err != nil {
+			report.Error("AMQP_URL", "не удалось подключиться: %v", err)
+		} else {
+			rabbitClient.Close()
+			report.OK("AMQP_URL", "подключение установлено")
+		}
+	default:
+		report.OK("AMQP_URL", "задан")
+	}
+	if err == nil {
+		if ttl := os.Getenv("FUNDING_TTL_MS"); ttl != "" && queue.TTL == 0 {
+			report.Warn("FUNDING_TTL_MS", "некорректное число %q, сообщения без TTL", ttl)
+		}
+		report.OK("FUNDING_QUEUE", "%s, TTL %d мс", queue.Queue, queue.TTL)
+	}
+
+	if report.Errors > 0 {
+		fmt.Fprintf(os.Stdout, "\nНайдено ошибок: %d\n", report.Errors)
+		return 1
+	}
+	return 0
+}